@@ -0,0 +1,552 @@
+package evengsdk
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+	"net/netip"
+	"sort"
+	"strconv"
+)
+
+// Attachment is a node interface connected to a network or, for links without a network, to the other end of the link.
+type Attachment struct {
+	NodeId    int
+	Interface string
+	Index     int
+	NetworkId int
+	// Link identifies the link when NetworkId is 0, both ends of a link share the same value.
+	Link string
+}
+
+// AddressPools are the pools an AddressPlan is allocated from. An invalid (zero) prefix disables the allocation.
+type AddressPools struct {
+	P2P4      netip.Prefix
+	Lan4      netip.Prefix
+	Loopback4 netip.Prefix
+	P2P6      netip.Prefix
+	Lan6      netip.Prefix
+	Loopback6 netip.Prefix
+	// P2PBits4 is the prefix length of IPv4 point-to-point links, usually 30 or 31.
+	P2PBits4 int
+	LanBits4 int
+	P2PBits6 int
+	LanBits6 int
+}
+
+// AddressPlan is the addressing of a lab: a loopback per node and a subnet per link.
+type AddressPlan struct {
+	Loopbacks []LoopbackAddress `json:"loopbacks"`
+	Links     []LinkAddress     `json:"links"`
+}
+
+type LoopbackAddress struct {
+	NodeId int          `json:"node_id"`
+	Node   string       `json:"node"`
+	IPv4   netip.Prefix `json:"ipv4"`
+	IPv6   netip.Prefix `json:"ipv6"`
+}
+
+type LinkAddress struct {
+	Key        string             `json:"key"`
+	NetworkId  int                `json:"network_id"`
+	Kind       string             `json:"kind"`
+	IPv4       netip.Prefix       `json:"ipv4"`
+	IPv6       netip.Prefix       `json:"ipv6"`
+	Interfaces []InterfaceAddress `json:"interfaces"`
+}
+
+// InterfaceAddress is the address of an interface, with the prefix length of its link.
+type InterfaceAddress struct {
+	NodeId    int          `json:"node_id"`
+	Node      string       `json:"node"`
+	Interface string       `json:"interface"`
+	IPv4      netip.Prefix `json:"ipv4"`
+	IPv6      netip.Prefix `json:"ipv6"`
+}
+
+const (
+	LinkKindP2P = "p2p"
+	LinkKindLan = "lan"
+)
+
+// DefaultAddressPools returns /31 links from 10.0.0.0/16, /24 LANs from 10.1.0.0/16, loopbacks from 10.255.255.0/24
+// and the IPv6 equivalents from fd00::/48.
+func DefaultAddressPools() AddressPools {
+	return AddressPools{
+		P2P4:      netip.MustParsePrefix("10.0.0.0/16"),
+		Lan4:      netip.MustParsePrefix("10.1.0.0/16"),
+		Loopback4: netip.MustParsePrefix("10.255.255.0/24"),
+		P2P6:      netip.MustParsePrefix("fd00:0:0:1::/64"),
+		Lan6:      netip.MustParsePrefix("fd00:0:1::/48"),
+		Loopback6: netip.MustParsePrefix("fd00:0:0:ffff::/64"),
+		P2PBits4:  31,
+		LanBits4:  24,
+		P2PBits6:  127,
+		LanBits6:  64,
+	}
+}
+
+// GetAttachments returns the connected interfaces of every node in the specified path.
+// The path should be the full path to the lab file, including the extension (e.g. /path/to/labfile.unl).
+// Serial interfaces are attached to the other end of their link.
+func (s *LabService) GetAttachments(path string) ([]Attachment, error) {
+	nodes, err := s.client.Node.GetNodes(path)
+	if err != nil {
+		return nil, err
+	}
+	var attachments []Attachment
+	for _, node := range nodes {
+		interfaces, err := s.client.Node.GetNodeInterfaces(path, node.Id)
+		if err != nil {
+			return nil, err
+		}
+		for index, intf := range interfaces.Ethernet {
+			if intf.NetworkId == 0 {
+				continue
+			}
+			attachments = append(attachments, Attachment{
+				NodeId:    node.Id,
+				Interface: intf.Name,
+				Index:     index,
+				NetworkId: intf.NetworkId,
+			})
+		}
+		for index, intf := range interfaces.Serial {
+			if intf.RemoteId == 0 {
+				continue
+			}
+			local := strconv.Itoa(node.Id) + "/" + strconv.Itoa(index)
+			remote := strconv.Itoa(intf.RemoteId) + "/" + strconv.Itoa(intf.RemoteIf)
+			if intf.RemoteId < node.Id || (intf.RemoteId == node.Id && intf.RemoteIf < index) {
+				local, remote = remote, local
+			}
+			attachments = append(attachments, Attachment{
+				NodeId:    node.Id,
+				Interface: intf.Name,
+				Index:     index,
+				Link:      string(InterfaceSerial) + ":" + local + "-" + remote,
+			})
+		}
+	}
+	return attachments, nil
+}
+
+// Attachments returns the connected interfaces of the topology.
+func (t *Topology) Attachments() []Attachment {
+	var attachments []Attachment
+	for _, link := range t.Links {
+		if link.SourceType != "node" {
+			continue
+		}
+		key := ""
+		if link.NetworkId == 0 {
+			key = link.Type + ":" + link.Source + ":" + link.SourceLabel
+		}
+		attachments = append(attachments, Attachment{
+			NodeId:    topologyId(link.Source),
+			Interface: link.SourceLabel,
+			Index:     -1,
+			NetworkId: link.NetworkId,
+			Link:      key,
+		})
+		if link.DestinationType == "node" {
+			attachments = append(attachments, Attachment{
+				NodeId:    topologyId(link.Destination),
+				Interface: link.DestinationLabel,
+				Index:     -1,
+				NetworkId: link.NetworkId,
+				Link:      key,
+			})
+		}
+	}
+	return attachments
+}
+
+// PlanAddresses computes the address plan of the lab with the specified path.
+// The path should be the full path to the lab file, including the extension (e.g. /path/to/labfile.unl).
+// previous is optional, see PlanAddresses.
+func (s *LabService) PlanAddresses(path string, pools AddressPools, previous *AddressPlan) (*AddressPlan, error) {
+	nodes, err := s.client.Node.GetNodes(path)
+	if err != nil {
+		return nil, err
+	}
+	networks, err := s.client.Network.GetNetworks(path)
+	if err != nil {
+		return nil, err
+	}
+	attachments, err := s.GetAttachments(path)
+	if err != nil {
+		return nil, err
+	}
+	return PlanAddresses(nodes, networks, attachments, pools, previous)
+}
+
+// PlanAddresses allocates a loopback per node and a subnet per link from the pools.
+// Links with two interfaces get a point-to-point subnet, links with more get a LAN subnet, cloud (pnet) networks are skipped.
+// The result only depends on the ids of the nodes and networks, so re-running it on the same lab yields the same plan.
+// When previous is not nil, its assignments are kept whenever they are still valid, so that adding or removing
+// links never renumbers the existing ones.
+func PlanAddresses(nodes map[string]Node, networks map[string]Network, attachments []Attachment, pools AddressPools, previous *AddressPlan) (*AddressPlan, error) {
+	plan := &AddressPlan{}
+	names := make(map[int]string, len(nodes))
+	ids := make([]int, 0, len(nodes))
+	for _, node := range nodes {
+		names[node.Id] = node.Name
+		ids = append(ids, node.Id)
+	}
+	sort.Ints(ids)
+
+	prevLoopbacks := make(map[int]LoopbackAddress)
+	prevLinks := make(map[string]LinkAddress)
+	if previous != nil {
+		for _, lo := range previous.Loopbacks {
+			prevLoopbacks[lo.NodeId] = lo
+		}
+		for _, link := range previous.Links {
+			prevLinks[link.Key] = link
+		}
+	}
+
+	lo4 := newAddressAllocator(pools.Loopback4, pools.Loopback4.Addr().BitLen())
+	lo6 := newAddressAllocator(pools.Loopback6, pools.Loopback6.Addr().BitLen())
+	for _, id := range ids {
+		prev := prevLoopbacks[id]
+		lo := LoopbackAddress{NodeId: id, Node: names[id]}
+		var err error
+		if lo.IPv4, err = lo4.allocate(id, prev.IPv4); err != nil {
+			return nil, err
+		}
+		if lo.IPv6, err = lo6.allocate(id, prev.IPv6); err != nil {
+			return nil, err
+		}
+		plan.Loopbacks = append(plan.Loopbacks, lo)
+	}
+
+	links := make(map[string]*LinkAddress)
+	for _, att := range attachments {
+		if network, ok := networks[strconv.Itoa(att.NetworkId)]; ok && network.Type.Cloud() {
+			continue
+		}
+		key := "network" + strconv.Itoa(att.NetworkId)
+		if att.NetworkId == 0 {
+			if att.Link == "" {
+				continue
+			}
+			key = "link:" + att.Link
+		}
+		link, ok := links[key]
+		if !ok {
+			link = &LinkAddress{Key: key, NetworkId: att.NetworkId}
+			links[key] = link
+		}
+		link.Interfaces = append(link.Interfaces, InterfaceAddress{NodeId: att.NodeId, Node: names[att.NodeId], Interface: att.Interface})
+	}
+	sorted := make([]*LinkAddress, 0, len(links))
+	for _, link := range links {
+		if len(link.Interfaces) < 2 {
+			continue
+		}
+		link.Kind = LinkKindP2P
+		if len(link.Interfaces) > 2 {
+			link.Kind = LinkKindLan
+		}
+		sort.Slice(link.Interfaces, func(i, j int) bool {
+			a, b := link.Interfaces[i], link.Interfaces[j]
+			if a.NodeId != b.NodeId {
+				return a.NodeId < b.NodeId
+			}
+			return a.Interface < b.Interface
+		})
+		sorted = append(sorted, link)
+	}
+	// Links with a network come first, so that their slot is not taken by a link without one.
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if (a.NetworkId == 0) != (b.NetworkId == 0) {
+			return a.NetworkId != 0
+		}
+		if a.NetworkId != b.NetworkId {
+			return a.NetworkId < b.NetworkId
+		}
+		return a.Key < b.Key
+	})
+
+	p2p4 := newAddressAllocator(pools.P2P4, pools.P2PBits4)
+	lan4 := newAddressAllocator(pools.Lan4, pools.LanBits4)
+	p2p6 := newAddressAllocator(pools.P2P6, pools.P2PBits6)
+	lan6 := newAddressAllocator(pools.Lan6, pools.LanBits6)
+	// Reserve the previous subnets first so that new links cannot take them.
+	for _, link := range sorted {
+		if prev, ok := prevLinks[link.Key]; ok && prev.Kind == link.Kind {
+			if link.Kind == LinkKindP2P {
+				p2p4.reserve(prev.IPv4)
+				p2p6.reserve(prev.IPv6)
+			} else {
+				lan4.reserve(prev.IPv4)
+				lan6.reserve(prev.IPv6)
+			}
+		}
+	}
+	for _, link := range sorted {
+		prev := prevLinks[link.Key]
+		if prev.Kind != link.Kind {
+			prev = LinkAddress{}
+		}
+		slot := link.NetworkId - 1
+		v4, v6 := p2p4, p2p6
+		if link.Kind == LinkKindLan {
+			v4, v6 = lan4, lan6
+		}
+		var err error
+		if link.IPv4, err = v4.allocateReserved(slot, prev.IPv4); err != nil {
+			return nil, err
+		}
+		if link.IPv6, err = v6.allocateReserved(slot, prev.IPv6); err != nil {
+			return nil, err
+		}
+		if err = assignInterfaceAddresses(link, prev); err != nil {
+			return nil, err
+		}
+		plan.Links = append(plan.Links, *link)
+	}
+	return plan, nil
+}
+
+// assignInterfaceAddresses numbers the interfaces of a link.
+// Point-to-point ends are numbered in order, LAN interfaces use the node id as host part.
+func assignInterfaceAddresses(link *LinkAddress, prev LinkAddress) error {
+	prevAddrs := make(map[string]InterfaceAddress)
+	for _, intf := range prev.Interfaces {
+		prevAddrs[strconv.Itoa(intf.NodeId)+"/"+intf.Interface] = intf
+	}
+	for _, family := range []struct {
+		subnet netip.Prefix
+		get    func(*InterfaceAddress) *netip.Prefix
+	}{
+		{link.IPv4, func(i *InterfaceAddress) *netip.Prefix { return &i.IPv4 }},
+		{link.IPv6, func(i *InterfaceAddress) *netip.Prefix { return &i.IPv6 }},
+	} {
+		if !family.subnet.IsValid() {
+			continue
+		}
+		hostBits := family.subnet.Addr().BitLen() - family.subnet.Bits()
+		first := 1
+		if hostBits <= 1 {
+			first = 0
+		}
+		used := make(map[netip.Addr]bool)
+		for i := range link.Interfaces {
+			intf := &link.Interfaces[i]
+			var addr netip.Addr
+			if p, ok := prevAddrs[strconv.Itoa(intf.NodeId)+"/"+intf.Interface]; ok {
+				addr = family.get(&p).Addr()
+			}
+			if !addressUsable(family.subnet, addr, first) || used[addr] {
+				offset := first + i
+				if link.Kind == LinkKindLan {
+					offset = intf.NodeId
+				}
+				addr = addressNth(family.subnet, big.NewInt(int64(offset)))
+				for next := first; !addressUsable(family.subnet, addr, first) || used[addr]; next++ {
+					addr = addressNth(family.subnet, big.NewInt(int64(next)))
+					if !family.subnet.Contains(addr) {
+						return errors.New("Subnet " + family.subnet.String() + " is too small for " + strconv.Itoa(len(link.Interfaces)) + " interfaces")
+					}
+				}
+			}
+			used[addr] = true
+			*family.get(intf) = netip.PrefixFrom(addr, family.subnet.Bits())
+		}
+	}
+	return nil
+}
+
+// addressUsable reports whether addr is a host address of subnet.
+func addressUsable(subnet netip.Prefix, addr netip.Addr, first int) bool {
+	if !addr.IsValid() || !subnet.Contains(addr) {
+		return false
+	}
+	if first == 0 {
+		return true
+	}
+	return addr != subnet.Masked().Addr() && (addr.Is6() || addr != addressLast(subnet))
+}
+
+// addressAllocator hands out subnets of a fixed size from a pool.
+// Allocators of host addresses, such as loopbacks, never hand out the network and broadcast addresses of the pool.
+type addressAllocator struct {
+	pool  netip.Prefix
+	bits  int
+	used  map[netip.Prefix]bool
+	slots *big.Int
+	// first and last bound the usable slots.
+	first, last *big.Int
+	hosts       bool
+}
+
+func newAddressAllocator(pool netip.Prefix, bits int) *addressAllocator {
+	a := &addressAllocator{pool: pool.Masked(), bits: bits, used: make(map[netip.Prefix]bool)}
+	if pool.IsValid() && bits >= pool.Bits() && bits <= pool.Addr().BitLen() {
+		a.slots = new(big.Int).Lsh(big.NewInt(1), uint(bits-pool.Bits()))
+		a.first, a.last = big.NewInt(0), new(big.Int).Sub(a.slots, big.NewInt(1))
+		// /31 and /32 pools have no network nor broadcast address.
+		a.hosts = bits == pool.Addr().BitLen()
+		if a.hosts && bits-pool.Bits() >= 2 {
+			a.first.SetInt64(1)
+			if pool.Addr().Is4() {
+				a.last.Sub(a.last, big.NewInt(1))
+			}
+		}
+	}
+	return a
+}
+
+func (a *addressAllocator) reserve(prefix netip.Prefix) {
+	if prefix.IsValid() {
+		a.used[prefix] = true
+	}
+}
+
+// slotOf returns the slot of a subnet of the pool.
+func (a *addressAllocator) slotOf(prefix netip.Prefix) *big.Int {
+	base := new(big.Int).SetBytes(a.pool.Addr().AsSlice())
+	offset := new(big.Int).Sub(new(big.Int).SetBytes(prefix.Addr().AsSlice()), base)
+	return offset.Rsh(offset, uint(a.pool.Addr().BitLen()-a.bits))
+}
+
+// allocate returns the subnet at slot, or prev when it is a valid subnet of the pool.
+// The first free slot after slot is used when it is already taken. Subnet slots past the end of the pool
+// start over from the beginning, while host slots past the usable addresses exhaust the pool.
+func (a *addressAllocator) allocate(slot int, prev netip.Prefix) (netip.Prefix, error) {
+	if !a.pool.IsValid() {
+		return netip.Prefix{}, nil
+	}
+	if a.slots == nil {
+		return netip.Prefix{}, errors.New("Invalid prefix length " + strconv.Itoa(a.bits) + " for pool " + a.pool.String())
+	}
+	if prev.IsValid() && prev.Bits() == a.bits && a.pool.Contains(prev.Addr()) && prev == prev.Masked() && !a.used[prev] {
+		if index := a.slotOf(prev); index.Cmp(a.first) >= 0 && index.Cmp(a.last) <= 0 {
+			a.used[prev] = true
+			return prev, nil
+		}
+	}
+	start := big.NewInt(int64(slot))
+	if start.Cmp(a.first) < 0 {
+		start.Set(a.first)
+	}
+	if start.Cmp(a.last) > 0 {
+		if a.hosts {
+			return netip.Prefix{}, errors.New("Pool " + a.pool.String() + " is exhausted")
+		}
+		start.Set(a.first)
+	}
+	size := new(big.Int).Lsh(big.NewInt(1), uint(a.pool.Addr().BitLen()-a.bits))
+	for i, n := new(big.Int).Set(start), 0; n <= len(a.used); n++ {
+		subnet := netip.PrefixFrom(addressNth(a.pool, new(big.Int).Mul(i, size)), a.bits)
+		if !a.used[subnet] {
+			a.used[subnet] = true
+			return subnet, nil
+		}
+		i.Add(i, big.NewInt(1))
+		if i.Cmp(a.last) > 0 {
+			i.Set(a.first)
+		}
+		if i.Cmp(start) == 0 {
+			break
+		}
+	}
+	return netip.Prefix{}, errors.New("Pool " + a.pool.String() + " is exhausted")
+}
+
+// allocateReserved is allocate for a subnet that may have been reserved beforehand.
+func (a *addressAllocator) allocateReserved(slot int, prev netip.Prefix) (netip.Prefix, error) {
+	if prev.IsValid() && a.used[prev] {
+		delete(a.used, prev)
+	}
+	return a.allocate(slot, prev)
+}
+
+// addressLast returns the last address of a prefix.
+func addressLast(p netip.Prefix) netip.Addr {
+	b := p.Masked().Addr().AsSlice()
+	for i := p.Bits(); i < len(b)*8; i++ {
+		b[i/8] |= 0x80 >> (i % 8)
+	}
+	addr, _ := netip.AddrFromSlice(b)
+	return addr
+}
+
+// addressNth returns the address at offset n from the start of the prefix.
+func addressNth(p netip.Prefix, n *big.Int) netip.Addr {
+	base := p.Masked().Addr()
+	sum := new(big.Int).Add(new(big.Int).SetBytes(base.AsSlice()), n)
+	b := sum.Bytes()
+	width := base.BitLen() / 8
+	if len(b) > width {
+		return netip.Addr{}
+	}
+	buf := make([]byte, width)
+	copy(buf[width-len(b):], b)
+	addr, _ := netip.AddrFromSlice(buf)
+	return addr
+}
+
+// WriteJSON writes the plan as indented JSON. The output can be decoded back into an AddressPlan.
+func (p *AddressPlan) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(p)
+}
+
+// WriteCSV writes the plan as CSV with one row per loopback and per interface.
+func (p *AddressPlan) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"kind", "link", "node_id", "node", "interface", "ipv4", "ipv6"})
+	for _, lo := range p.Loopbacks {
+		cw.Write([]string{"loopback", "", strconv.Itoa(lo.NodeId), lo.Node, "", prefixString(lo.IPv4), prefixString(lo.IPv6)})
+	}
+	for _, link := range p.Links {
+		for _, intf := range link.Interfaces {
+			cw.Write([]string{link.Kind, link.Key, strconv.Itoa(intf.NodeId), intf.Node, intf.Interface, prefixString(intf.IPv4), prefixString(intf.IPv6)})
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// TemplateVars returns the plan as template variables keyed by node name.
+// Each node has "loopback4", "loopback6" and "interfaces", a map of interface name to its "ipv4" and "ipv6" addresses.
+func (p *AddressPlan) TemplateVars() map[string]map[string]interface{} {
+	vars := make(map[string]map[string]interface{})
+	node := func(name string) map[string]interface{} {
+		if _, ok := vars[name]; !ok {
+			vars[name] = map[string]interface{}{"interfaces": map[string]map[string]string{}}
+		}
+		return vars[name]
+	}
+	for _, lo := range p.Loopbacks {
+		v := node(lo.Node)
+		v["loopback4"] = prefixString(lo.IPv4)
+		v["loopback6"] = prefixString(lo.IPv6)
+	}
+	for _, link := range p.Links {
+		for _, intf := range link.Interfaces {
+			interfaces := node(intf.Node)["interfaces"].(map[string]map[string]string)
+			interfaces[intf.Interface] = map[string]string{
+				"ipv4": prefixString(intf.IPv4),
+				"ipv6": prefixString(intf.IPv6),
+			}
+		}
+	}
+	return vars
+}
+
+func prefixString(p netip.Prefix) string {
+	if !p.IsValid() {
+		return ""
+	}
+	return p.String()
+}
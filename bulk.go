@@ -0,0 +1,197 @@
+package evengsdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// BulkMode is what a bulk operation does after a node fails.
+type BulkMode int
+
+const (
+	// ContinueOnError runs the operation on every node.
+	ContinueOnError BulkMode = iota
+	// FailFast skips the nodes not started yet after the first failure.
+	FailFast
+)
+
+// BulkOptions configures the bulk node operations.
+type BulkOptions struct {
+	// Concurrency is the number of nodes handled at the same time. Defaults to 4.
+	Concurrency int
+	// Mode defaults to ContinueOnError.
+	Mode BulkMode
+}
+
+// BulkItem is the outcome of a bulk operation for a single node.
+type BulkItem struct {
+	NodeId   int
+	Err      error
+	Duration time.Duration
+	// Skipped is true when the operation did not run for the node, because of FailFast or of the context.
+	Skipped bool
+}
+
+// Ok reports whether the operation succeeded for the node.
+func (i BulkItem) Ok() bool {
+	return i.Err == nil && !i.Skipped
+}
+
+// BulkResult is the outcome of a bulk operation, with one item per node in the order given.
+type BulkResult struct {
+	Items    []BulkItem
+	Duration time.Duration
+}
+
+// Failed returns the items of the nodes for which the operation failed or was skipped.
+func (r *BulkResult) Failed() []BulkItem {
+	var failed []BulkItem
+	for _, item := range r.Items {
+		if !item.Ok() {
+			failed = append(failed, item)
+		}
+	}
+	return failed
+}
+
+// Err joins the errors of the failed nodes, it is nil when every node succeeded.
+func (r *BulkResult) Err() error {
+	var errs []error
+	for _, item := range r.Failed() {
+		if item.Skipped {
+			errs = append(errs, errors.New("node "+strconv.Itoa(item.NodeId)+": Skipped"))
+			continue
+		}
+		errs = append(errs, errors.New("node "+strconv.Itoa(item.NodeId)+": "+item.Err.Error()))
+	}
+	return errors.Join(errs...)
+}
+
+// RunBulk calls action for every id with a pool of workers and collects the outcome of each call.
+// The error is BulkResult.Err.
+func RunBulk(ctx context.Context, ids []int, opts BulkOptions, action func(id int) error) (*BulkResult, error) {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	start := time.Now()
+	result := &BulkResult{Items: make([]BulkItem, len(ids))}
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency && w < len(ids); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				item := &result.Items[i]
+				if ctx.Err() != nil {
+					item.Skipped = true
+					continue
+				}
+				begin := time.Now()
+				item.Err = action(item.NodeId)
+				item.Duration = time.Since(begin)
+				if item.Err != nil && opts.Mode == FailFast {
+					cancel()
+				}
+			}
+		}()
+	}
+	for i, id := range ids {
+		result.Items[i].NodeId = id
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	result.Duration = time.Since(start)
+	return result, result.Err()
+}
+
+// BulkStart starts the nodes with the specified ids in the specified path, see RunBulk.
+// The path should be the full path to the lab file, including the extension (e.g. /path/to/labfile.unl).
+func (s *NodeService) BulkStart(ctx context.Context, path string, ids []int, opts BulkOptions) (*BulkResult, error) {
+	return RunBulk(ctx, ids, opts, func(id int) error { return s.StartNode(path, id) })
+}
+
+// BulkStop stops the nodes with the specified ids in the specified path, see RunBulk.
+// The path should be the full path to the lab file, including the extension (e.g. /path/to/labfile.unl).
+func (s *NodeService) BulkStop(ctx context.Context, path string, ids []int, opts BulkOptions) (*BulkResult, error) {
+	return RunBulk(ctx, ids, opts, func(id int) error { return s.StopNode(path, id) })
+}
+
+// BulkWipe wipes the nodes with the specified ids in the specified path, see RunBulk.
+// The path should be the full path to the lab file, including the extension (e.g. /path/to/labfile.unl).
+func (s *NodeService) BulkWipe(ctx context.Context, path string, ids []int, opts BulkOptions) (*BulkResult, error) {
+	return RunBulk(ctx, ids, opts, func(id int) error { return s.WipeNode(path, id) })
+}
+
+// BulkDelete deletes the nodes with the specified ids in the specified path, see RunBulk.
+// The path should be the full path to the lab file, including the extension (e.g. /path/to/labfile.unl).
+func (s *NodeService) BulkDelete(ctx context.Context, path string, ids []int, opts BulkOptions) (*BulkResult, error) {
+	return RunBulk(ctx, ids, opts, func(id int) error { return s.DeleteNode(path, id) })
+}
+
+// BulkUpdate updates the nodes in the specified path, each node is identified by its Id, see RunBulk.
+// The path should be the full path to the lab file, including the extension (e.g. /path/to/labfile.unl).
+// Each node is compared with the node on the server and only the fields that differ are sent, so nodes listed
+// by GetNodes leave the settings they lack untouched.
+func (s *NodeService) BulkUpdate(ctx context.Context, path string, nodes []Node, opts BulkOptions) (*BulkResult, error) {
+	ids := make([]int, len(nodes))
+	byId := make(map[int]*Node, len(nodes))
+	for i := range nodes {
+		ids[i] = nodes[i].Id
+		byId[nodes[i].Id] = &nodes[i]
+	}
+	if len(byId) != len(nodes) {
+		return nil, errors.New("Duplicate node id in bulk update")
+	}
+	return RunBulk(ctx, ids, opts, func(id int) error { return s.updateNodeChanges(path, byId[id]) })
+}
+
+// updateNodeChanges sends the fields of the node that differ from the node on the server.
+func (s *NodeService) updateNodeChanges(path string, node *Node) error {
+	current, err := s.GetNode(path, node.Id)
+	if err != nil {
+		return err
+	}
+	want, err := nodeFieldValues(node)
+	if err != nil {
+		return err
+	}
+	have, err := nodeFieldValues(current)
+	if err != nil {
+		return err
+	}
+	changes := make(map[string]interface{})
+	for name, value := range want {
+		// The status and console url are run time state, they are not updated.
+		if name == "status" || name == "url" {
+			continue
+		}
+		if !bytes.Equal(value, have[name]) {
+			changes[name] = value
+		}
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	return s.updateNodeFields(path, node.Id, changes)
+}
+
+// nodeFieldValues returns the JSON encoding of every field of the node, keyed by JSON name.
+func nodeFieldValues(node *Node) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(node)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	err = json.Unmarshal(data, &fields)
+	return fields, err
+}
@@ -0,0 +1,41 @@
+// Command eveng-inventory is an Ansible dynamic inventory script for an EVE-NG lab.
+//
+// It reads the connection settings from the environment:
+//
+//	EVE_HOST, EVE_USER, EVE_PASSWORD  the EVE-NG server and credentials
+//	EVE_LAB                           the full path to the lab file (e.g. /path/to/labfile.unl)
+//	EVE_INVENTORY_GROUP_BY            "template" (default) or "type"
+//
+// Usage:
+//
+//	ansible-inventory -i eveng-inventory --list
+package main
+
+import (
+	"fmt"
+	"github.com/CorentinPtrl/evengsdk"
+	"os"
+)
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	client, err := evengsdk.NewBasicAuthClient(os.Getenv("EVE_USER"), os.Getenv("EVE_PASSWORD"), "0", os.Getenv("EVE_HOST"))
+	if err != nil {
+		return err
+	}
+	opts := evengsdk.InventoryOptions{}
+	if os.Getenv("EVE_INVENTORY_GROUP_BY") == "type" {
+		opts.GroupBy = evengsdk.GroupByType
+	}
+	inventory, err := client.Lab.GetInventory(os.Getenv("EVE_LAB"), opts)
+	if err != nil {
+		return err
+	}
+	return inventory.RunScript(os.Args[1:], os.Stdout)
+}
@@ -0,0 +1,233 @@
+package evengsdk
+
+import (
+	"bytes"
+	"net/netip"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// ConfigPeer is a node on the other side of a link.
+type ConfigPeer struct {
+	Node      string
+	NodeId    int
+	Interface string
+	Address   netip.Addr
+	Address6  netip.Addr
+}
+
+// ConfigInterface is a connected interface of the node being rendered.
+type ConfigInterface struct {
+	Name      string
+	NetworkId int
+	Address   netip.Addr
+	Prefix    netip.Prefix
+	Address6  netip.Addr
+	Prefix6   netip.Prefix
+	Peers     []ConfigPeer
+}
+
+// ConfigData is the data passed to a config template.
+type ConfigData struct {
+	Node       Node
+	Hostname   string
+	RouterId   netip.Addr
+	Loopback   netip.Prefix
+	Loopback6  netip.Prefix
+	Interfaces []ConfigInterface
+	Neighbors  []ConfigPeer
+	Vars       map[string]interface{}
+}
+
+// ConfigResult is the outcome of rendering and pushing the config of a single node.
+type ConfigResult struct {
+	NodeId  int
+	Name    string
+	Config  string
+	Skipped bool
+	Err     error
+}
+
+// ConfigRenderer renders startup-configs from text/template skeletons and the lab topology.
+type ConfigRenderer struct {
+	// Templates maps a node template (e.g. vios) to its config skeleton.
+	Templates map[string]*template.Template
+	// Default is used for nodes whose template has no entry in Templates. Nodes are skipped when it is nil.
+	Default *template.Template
+	// Pools are used to plan the addressing of the lab, router ids are the IPv4 loopbacks.
+	Pools AddressPools
+	// Plan is used instead of planning the addressing from Pools when it is not nil.
+	Plan *AddressPlan
+	// Vars are passed to every template, NodeVars to the node with the matching name only.
+	Vars     map[string]interface{}
+	NodeVars map[string]map[string]interface{}
+}
+
+// ConfigFuncs are the functions available in config templates.
+var ConfigFuncs = template.FuncMap{
+	"netmask":  configNetmask,
+	"hostmask": configHostmask,
+	"join":     strings.Join,
+	"lower":    strings.ToLower,
+	"upper":    strings.ToUpper,
+}
+
+// NewConfigRenderer returns a ConfigRenderer using the DefaultAddressPools.
+func NewConfigRenderer() *ConfigRenderer {
+	return &ConfigRenderer{
+		Templates: make(map[string]*template.Template),
+		Pools:     DefaultAddressPools(),
+		Vars:      make(map[string]interface{}),
+		NodeVars:  make(map[string]map[string]interface{}),
+	}
+}
+
+// AddTemplate parses text as the config skeleton of the specified node template.
+// An empty nodeTemplate sets the Default skeleton.
+func (r *ConfigRenderer) AddTemplate(nodeTemplate string, text string) error {
+	name := nodeTemplate
+	if name == "" {
+		name = "default"
+	}
+	tmpl, err := template.New(name).Funcs(ConfigFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return err
+	}
+	if nodeTemplate == "" {
+		r.Default = tmpl
+	} else {
+		r.Templates[nodeTemplate] = tmpl
+	}
+	return nil
+}
+
+// ConfigData computes the template data of every node of the topology, keyed by node id.
+func (r *ConfigRenderer) ConfigData(t *Topology) (map[int]*ConfigData, error) {
+	plan := r.Plan
+	if plan == nil {
+		var err error
+		plan, err = PlanAddresses(t.Nodes, t.Networks, t.Attachments(), r.Pools, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	data := make(map[int]*ConfigData, len(t.Nodes))
+	for _, node := range t.Nodes {
+		vars := make(map[string]interface{}, len(r.Vars))
+		for k, v := range r.Vars {
+			vars[k] = v
+		}
+		for k, v := range r.NodeVars[node.Name] {
+			vars[k] = v
+		}
+		data[node.Id] = &ConfigData{
+			Node:     node,
+			Hostname: node.Name,
+			Vars:     vars,
+		}
+	}
+	for _, lo := range plan.Loopbacks {
+		if d, ok := data[lo.NodeId]; ok {
+			d.RouterId = lo.IPv4.Addr()
+			d.Loopback = lo.IPv4
+			d.Loopback6 = lo.IPv6
+		}
+	}
+	for _, link := range plan.Links {
+		for i, end := range link.Interfaces {
+			d, ok := data[end.NodeId]
+			if !ok {
+				continue
+			}
+			intf := ConfigInterface{
+				Name:      end.Interface,
+				NetworkId: link.NetworkId,
+				Address:   end.IPv4.Addr(),
+				Prefix:    link.IPv4,
+				Address6:  end.IPv6.Addr(),
+				Prefix6:   link.IPv6,
+			}
+			for j, peer := range link.Interfaces {
+				if i != j {
+					intf.Peers = append(intf.Peers, ConfigPeer{
+						Node:      peer.Node,
+						NodeId:    peer.NodeId,
+						Interface: peer.Interface,
+						Address:   peer.IPv4.Addr(),
+						Address6:  peer.IPv6.Addr(),
+					})
+				}
+			}
+			d.Interfaces = append(d.Interfaces, intf)
+			d.Neighbors = append(d.Neighbors, intf.Peers...)
+		}
+	}
+	return data, nil
+}
+
+// Render renders the config of every node of the topology.
+// The results are sorted by node id, nodes without a matching template are marked as skipped.
+func (r *ConfigRenderer) Render(t *Topology) ([]ConfigResult, error) {
+	data, err := r.ConfigData(t)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]ConfigResult, 0, len(data))
+	for _, d := range data {
+		result := ConfigResult{NodeId: d.Node.Id, Name: d.Node.Name}
+		tmpl, ok := r.Templates[d.Node.Template]
+		if !ok {
+			tmpl = r.Default
+		}
+		if tmpl == nil {
+			result.Skipped = true
+		} else {
+			var buf bytes.Buffer
+			result.Err = tmpl.Execute(&buf, d)
+			result.Config = buf.String()
+		}
+		results = append(results, result)
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].NodeId < results[j].NodeId })
+	return results, nil
+}
+
+// PushConfigs renders the config of every node in the specified path and uploads it with UpdateNodeConfig.
+// The path should be the full path to the lab file, including the extension (e.g. /path/to/labfile.unl).
+// A failing node does not stop the others, the error of each node is reported in its ConfigResult.
+func (s *NodeService) PushConfigs(path string, r *ConfigRenderer) ([]ConfigResult, error) {
+	topology, err := s.client.Lab.GetLabTopology(path)
+	if err != nil {
+		return nil, err
+	}
+	results, err := r.Render(topology)
+	if err != nil {
+		return nil, err
+	}
+	for i, result := range results {
+		if result.Skipped || result.Err != nil {
+			continue
+		}
+		results[i].Err = s.UpdateNodeConfig(path, result.NodeId, result.Config)
+	}
+	return results, nil
+}
+
+func configNetmask(p netip.Prefix) string {
+	b := make([]byte, p.Addr().BitLen()/8)
+	for i := 0; i < p.Bits(); i++ {
+		b[i/8] |= 0x80 >> (i % 8)
+	}
+	mask, _ := netip.AddrFromSlice(b)
+	return mask.String()
+}
+
+func configHostmask(p netip.Prefix) string {
+	b := make([]byte, p.Addr().BitLen()/8)
+	for i := p.Bits(); i < len(b)*8; i++ {
+		b[i/8] |= 0x80 >> (i % 8)
+	}
+	mask, _ := netip.AddrFromSlice(b)
+	return mask.String()
+}
@@ -0,0 +1,230 @@
+package console
+
+import (
+	"context"
+	"errors"
+	"github.com/CorentinPtrl/evengsdk"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CaptureOptions configures Capture and CaptureLab.
+type CaptureOptions struct {
+	// Dir receives a "<node name>_<node id>.log" file per node when it is not empty.
+	Dir string
+	// MaxSize is the size in bytes at which a log file is rotated, 0 disables rotation.
+	MaxSize int64
+	// MaxFiles is the number of rotated files kept next to the log file, "<node name>_<node id>.log.1" being the newest. Defaults to 5.
+	MaxFiles int
+	// Writer receives every line prefixed with the node name when it is not nil.
+	Writer io.Writer
+	// TimeFormat is the layout of the timestamp starting every line. Defaults to RFC 3339 with milliseconds.
+	TimeFormat string
+	// Reconnect is the delay before dialing a console again after it dropped. Defaults to 5 seconds.
+	Reconnect time.Duration
+	// FlushAfter is how long output without a line ending, such as a prompt, is held before being written as a line. Defaults to 2 seconds.
+	FlushAfter time.Duration
+}
+
+// Capture records the console output of a node until the context is done.
+// The console is dialed again whenever it drops or cannot be reached, so the capture survives node restarts.
+// It returns nil once the context is done, or the first error writing the output.
+func Capture(ctx context.Context, node *evengsdk.Node, opts CaptureOptions) error {
+	if opts.Dir == "" && opts.Writer == nil {
+		return errors.New("No capture output")
+	}
+	if opts.TimeFormat == "" {
+		opts.TimeFormat = "2006-01-02T15:04:05.000Z07:00"
+	}
+	if opts.Reconnect == 0 {
+		opts.Reconnect = 5 * time.Second
+	}
+	if opts.FlushAfter == 0 {
+		opts.FlushAfter = 2 * time.Second
+	}
+	var file *rotatingFile
+	if opts.Dir != "" {
+		if err := os.MkdirAll(opts.Dir, 0o755); err != nil {
+			return err
+		}
+		var err error
+		file, err = openRotatingFile(filepath.Join(opts.Dir, transcriptName(node)), opts.MaxSize, opts.MaxFiles)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+	}
+	emit := func(t time.Time, line string) error {
+		stamp := t.Format(opts.TimeFormat)
+		if file != nil {
+			if _, err := io.WriteString(file, stamp+" "+line+"\n"); err != nil {
+				return err
+			}
+		}
+		if opts.Writer != nil {
+			if _, err := io.WriteString(opts.Writer, stamp+" "+node.Name+": "+line+"\n"); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for {
+		conn, err := Open(ctx, node)
+		if err == nil {
+			err = captureConn(ctx, conn, opts.FlushAfter, emit)
+			conn.Close()
+			if err != nil {
+				return err
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(opts.Reconnect):
+		}
+	}
+}
+
+// captureConn emits the lines read from conn until it drops or the context is done.
+// Only the errors of emit are returned.
+func captureConn(ctx context.Context, conn *Conn, flushAfter time.Duration, emit func(time.Time, string) error) error {
+	for {
+		readCtx, cancel := context.WithTimeout(ctx, flushAfter)
+		line, err := conn.ReadLine(readCtx)
+		cancel()
+		if err != nil {
+			line = strings.TrimRight(conn.Drain(), "\r")
+			if line != "" {
+				if err := emit(time.Now(), line); err != nil {
+					return err
+				}
+			}
+			if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+				continue
+			}
+			return nil
+		}
+		if err := emit(time.Now(), line); err != nil {
+			return err
+		}
+	}
+}
+
+// CaptureLab records the console output of every node in the specified path until the context is done, see Capture.
+// The path should be the full path to the lab file, including the extension (e.g. /path/to/labfile.unl).
+// Nodes without a telnet console are skipped, as are nodes added to the lab after the call.
+// It blocks, run it in its own goroutine to capture while the lab is used.
+func CaptureLab(ctx context.Context, client *evengsdk.Client, path string, opts CaptureOptions) error {
+	nodes, err := client.Node.GetNodes(path)
+	if err != nil {
+		return err
+	}
+	if opts.Writer != nil {
+		opts.Writer = &lockedWriter{w: opts.Writer}
+	}
+	sorted := make([]evengsdk.Node, 0, len(nodes))
+	for _, node := range nodes {
+		if node.Console != "" && node.Console != evengsdk.ConsoleTelnet {
+			continue
+		}
+		sorted = append(sorted, node)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Id < sorted[j].Id })
+
+	errs := make([]error, len(sorted))
+	var wg sync.WaitGroup
+	for i := range sorted {
+		wg.Add(1)
+		go func(node evengsdk.Node, err *error) {
+			defer wg.Done()
+			if e := Capture(ctx, &node, opts); e != nil {
+				*err = errors.New(node.Name + ": " + e.Error())
+			}
+		}(sorted[i], &errs[i])
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// lockedWriter serializes the writes of concurrent captures.
+type lockedWriter struct {
+	lock sync.Mutex
+	w    io.Writer
+}
+
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.w.Write(p)
+}
+
+// rotatingFile is a log file renamed to "<path>.1" once it reaches maxSize, shifting the older files.
+type rotatingFile struct {
+	path     string
+	maxSize  int64
+	maxFiles int
+	file     *os.File
+	size     int64
+}
+
+func openRotatingFile(path string, maxSize int64, maxFiles int) (*rotatingFile, error) {
+	if maxFiles <= 0 {
+		maxFiles = 5
+	}
+	r := &rotatingFile{path: path, maxSize: maxSize, maxFiles: maxFiles}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *rotatingFile) open() error {
+	file, err := os.OpenFile(r.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	r.file, r.size = file, info.Size()
+	return nil
+}
+
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	if r.maxSize > 0 && r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+func (r *rotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return err
+	}
+	for i := r.maxFiles - 1; i >= 1; i-- {
+		err := os.Rename(r.path+"."+strconv.Itoa(i), r.path+"."+strconv.Itoa(i+1))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	if err := os.Rename(r.path, r.path+".1"); err != nil {
+		return err
+	}
+	return r.open()
+}
+
+func (r *rotatingFile) Close() error {
+	return r.file.Close()
+}
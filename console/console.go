@@ -0,0 +1,358 @@
+// Package console implements a telnet client for EVE-NG node consoles.
+package console
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/CorentinPtrl/evengsdk"
+	"io"
+	"net"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Telnet commands and options, see RFC 854 and RFC 855.
+const (
+	cmdSE   = 240
+	cmdSB   = 250
+	cmdWILL = 251
+	cmdWONT = 252
+	cmdDO   = 253
+	cmdDONT = 254
+	cmdIAC  = 255
+
+	optEcho            = 1
+	optSuppressGoAhead = 3
+)
+
+// MaxBuffer is the maximum amount of unread console output kept by a Conn, older output is discarded.
+const MaxBuffer = 1 << 20
+
+// Conn is a telnet connection to a node console.
+// It implements io.ReadWriteCloser, Read returns the console output with the telnet commands removed.
+// Reads are not safe for concurrent use, writes are.
+type Conn struct {
+	conn    net.Conn
+	decoder *decoder
+	pending []byte
+	wlock   sync.Mutex
+	// LineEnding is appended to every line sent with SendLine. Defaults to "\r\n".
+	LineEnding string
+}
+
+// Dial connects to a console address, either host:port or a telnet:// URL.
+func Dial(ctx context.Context, address string) (*Conn, error) {
+	if strings.Contains(address, "://") {
+		u, err := url.Parse(address)
+		if err != nil {
+			return nil, err
+		}
+		if u.Scheme != "telnet" {
+			return nil, errors.New("Unsupported console scheme " + u.Scheme)
+		}
+		address = u.Host
+	}
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	return NewConn(conn), nil
+}
+
+// Open connects to the console of a node, as returned by NodeService.GetNode.
+// The node should be running and use a telnet console.
+func Open(ctx context.Context, node *evengsdk.Node) (*Conn, error) {
+	if node.Console != "" && node.Console != evengsdk.ConsoleTelnet {
+		return nil, errors.New("Node " + node.Name + " has a " + node.Console.String() + " console, not telnet")
+	}
+	host, port, err := node.ConsoleAddress()
+	if err != nil {
+		return nil, err
+	}
+	return Dial(ctx, net.JoinHostPort(host, strconv.Itoa(port)))
+}
+
+// NewConn wraps an established connection to a telnet server.
+func NewConn(conn net.Conn) *Conn {
+	c := &Conn{conn: conn, LineEnding: "\r\n"}
+	c.decoder = &decoder{r: conn, reply: c.writeRaw}
+	return c
+}
+
+// Read reads console output.
+func (c *Conn) Read(p []byte) (int, error) {
+	if len(c.pending) > 0 {
+		n := copy(p, c.pending)
+		c.pending = c.pending[n:]
+		return n, nil
+	}
+	return c.decoder.Read(p)
+}
+
+// Write sends data to the console, escaping the telnet IAC byte.
+func (c *Conn) Write(p []byte) (int, error) {
+	escaped := bytes.ReplaceAll(p, []byte{cmdIAC}, []byte{cmdIAC, cmdIAC})
+	if _, err := c.writeRaw(escaped); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (c *Conn) writeRaw(p []byte) (int, error) {
+	c.wlock.Lock()
+	defer c.wlock.Unlock()
+	return c.conn.Write(p)
+}
+
+// Close closes the connection.
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
+
+// withContext applies the context deadline and cancellation to the I/O done by f, through setDeadline.
+// Reads and writes pass the read or the write deadline, so that a write does not clear the deadline of a read in progress.
+func (c *Conn) withContext(ctx context.Context, setDeadline func(time.Time) error, f func() error) error {
+	if deadline, ok := ctx.Deadline(); ok {
+		setDeadline(deadline)
+	}
+	stop := context.AfterFunc(ctx, func() {
+		setDeadline(time.Now())
+	})
+	err := f()
+	stop()
+	setDeadline(time.Time{})
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		// Only the context sets deadlines, and the connection deadline may expire slightly before the context does.
+		if _, ok := ctx.Deadline(); ok || ctx.Err() != nil {
+			<-ctx.Done()
+			return ctx.Err()
+		}
+	}
+	return err
+}
+
+// fill reads more console output into the pending buffer.
+func (c *Conn) fill() error {
+	buf := make([]byte, 4096)
+	n, err := c.decoder.Read(buf)
+	c.pending = append(c.pending, buf[:n]...)
+	if len(c.pending) > MaxBuffer {
+		c.pending = c.pending[len(c.pending)-MaxBuffer:]
+	}
+	return err
+}
+
+// Expect reads console output until re matches it.
+// It returns the output up to the end of the match, the rest is left for the next read.
+func (c *Conn) Expect(ctx context.Context, re *regexp.Regexp) (string, error) {
+	_, out, err := c.ExpectAny(ctx, re)
+	return out, err
+}
+
+// ExpectAny reads console output until one of the patterns matches it.
+// It returns the index of the pattern with the earliest match and the output up to the end of that match.
+// On error, the output read so far is kept for the next read, see Drain.
+func (c *Conn) ExpectAny(ctx context.Context, patterns ...*regexp.Regexp) (int, string, error) {
+	index := -1
+	var out string
+	err := c.withContext(ctx, c.conn.SetReadDeadline, func() error {
+		for {
+			end := -1
+			for i, re := range patterns {
+				if loc := re.FindIndex(c.pending); loc != nil && (index < 0 || loc[0] < end) {
+					index, end = i, loc[0]
+				}
+			}
+			if index >= 0 {
+				end = patterns[index].FindIndex(c.pending)[1]
+				out = string(c.pending[:end])
+				c.pending = c.pending[end:]
+				return nil
+			}
+			if err := c.fill(); err != nil {
+				return err
+			}
+		}
+	})
+	return index, out, err
+}
+
+// Drain returns and discards the console output that was read but not consumed yet.
+func (c *Conn) Drain() string {
+	out := string(c.pending)
+	c.pending = nil
+	return out
+}
+
+// ReadLine reads a line of console output, without its line ending.
+func (c *Conn) ReadLine(ctx context.Context) (string, error) {
+	var line string
+	err := c.withContext(ctx, c.conn.SetReadDeadline, func() error {
+		for {
+			if i := bytes.IndexByte(c.pending, '\n'); i >= 0 {
+				line = strings.TrimRight(string(c.pending[:i]), "\r")
+				c.pending = c.pending[i+1:]
+				return nil
+			}
+			if err := c.fill(); err != nil {
+				if errors.Is(err, io.EOF) && len(c.pending) > 0 {
+					line = strings.TrimRight(string(c.pending), "\r")
+					c.pending = nil
+					return nil
+				}
+				return err
+			}
+		}
+	})
+	return line, err
+}
+
+// SendLine sends a line followed by LineEnding.
+func (c *Conn) SendLine(ctx context.Context, line string) error {
+	return c.withContext(ctx, c.conn.SetWriteDeadline, func() error {
+		_, err := c.Write([]byte(line + c.LineEnding))
+		return err
+	})
+}
+
+// decoder strips telnet commands from the stream and answers option negotiations.
+// The client agrees to the server echoing and suppressing go-ahead, and refuses every other option.
+type decoder struct {
+	r     io.Reader
+	reply func([]byte) (int, error)
+	state int
+	cmd   byte
+	raw   []byte
+	// him and us are the enabled options of the server and of the client, see RFC 1143.
+	him, us [256]bool
+}
+
+const (
+	stateData = iota
+	stateIAC
+	stateOption
+	stateSub
+	stateSubIAC
+	stateCR
+)
+
+func (d *decoder) Read(p []byte) (int, error) {
+	for {
+		if cap(d.raw) < len(p) {
+			d.raw = make([]byte, len(p))
+		}
+		n, err := d.r.Read(d.raw[:len(p)])
+		out := 0
+		for _, b := range d.raw[:n] {
+			switch d.state {
+			case stateData:
+				switch b {
+				case cmdIAC:
+					d.state = stateIAC
+				case '\r':
+					d.state = stateCR
+					p[out] = b
+					out++
+				default:
+					p[out] = b
+					out++
+				}
+			case stateCR:
+				// CR NUL is a bare carriage return.
+				d.state = stateData
+				if b == 0 {
+					continue
+				}
+				if b == cmdIAC {
+					d.state = stateIAC
+					continue
+				}
+				if b == '\r' {
+					d.state = stateCR
+				}
+				p[out] = b
+				out++
+			case stateIAC:
+				switch b {
+				case cmdIAC:
+					d.state = stateData
+					p[out] = b
+					out++
+				case cmdWILL, cmdWONT, cmdDO, cmdDONT:
+					d.state = stateOption
+					d.cmd = b
+				case cmdSB:
+					d.state = stateSub
+				default:
+					d.state = stateData
+				}
+			case stateOption:
+				d.state = stateData
+				if resp := d.negotiate(d.cmd, b); resp != nil {
+					if _, werr := d.reply(resp); werr != nil && err == nil {
+						err = werr
+					}
+				}
+			case stateSub:
+				if b == cmdIAC {
+					d.state = stateSubIAC
+				}
+			case stateSubIAC:
+				if b == cmdSE {
+					d.state = stateData
+				} else {
+					d.state = stateSub
+				}
+			}
+		}
+		if out > 0 || err != nil {
+			return out, err
+		}
+	}
+}
+
+// negotiate returns the answer to an option negotiation, or nil when none is needed.
+// It follows the Q method of RFC 1143: requests that do not change the state of an option are not answered,
+// so that a server sending an option again cannot start a negotiation loop. The client never asks for options itself.
+func (d *decoder) negotiate(cmd byte, opt byte) []byte {
+	switch cmd {
+	case cmdWILL:
+		if d.him[opt] {
+			return nil
+		}
+		if opt == optEcho || opt == optSuppressGoAhead {
+			d.him[opt] = true
+			return []byte{cmdIAC, cmdDO, opt}
+		}
+		return []byte{cmdIAC, cmdDONT, opt}
+	case cmdWONT:
+		if !d.him[opt] {
+			return nil
+		}
+		d.him[opt] = false
+		return []byte{cmdIAC, cmdDONT, opt}
+	case cmdDO:
+		if d.us[opt] {
+			return nil
+		}
+		if opt == optSuppressGoAhead {
+			d.us[opt] = true
+			return []byte{cmdIAC, cmdWILL, opt}
+		}
+		return []byte{cmdIAC, cmdWONT, opt}
+	case cmdDONT:
+		if !d.us[opt] {
+			return nil
+		}
+		d.us[opt] = false
+		return []byte{cmdIAC, cmdWONT, opt}
+	}
+	return nil
+}
@@ -0,0 +1,265 @@
+package console
+
+import (
+	"context"
+	"errors"
+	"github.com/CorentinPtrl/evengsdk"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Step waits for a prompt, then answers it.
+type Step struct {
+	Name string
+	// Expect is the prompt to wait for. The step sends right away when it is nil.
+	Expect *regexp.Regexp
+	// Send is sent line by line once Expect matched, an empty Send sends a bare line ending.
+	Send string
+	// NoSend only waits for Expect.
+	NoSend bool
+	// Timeout overrides the playbook Timeout for this step.
+	Timeout time.Duration
+	// Retries overrides the playbook Retries for this step, a negative value disables them.
+	Retries int
+	// Optional steps are skipped instead of failing when Expect does not match.
+	Optional bool
+	// Reactions are checked before the playbook reactions while waiting for Expect.
+	Reactions []Reaction
+}
+
+// Reaction answers a prompt that may show up at any time, such as a pager or a login prompt.
+type Reaction struct {
+	Pattern *regexp.Regexp
+	Send    string
+	// Raw sends Send without a line ending.
+	Raw bool
+}
+
+// Playbook is a console dialogue: a list of steps, and reactions checked while waiting for each step.
+type Playbook struct {
+	Name      string
+	Steps     []Step
+	Reactions []Reaction
+	// Timeout is the time given to each step to match, it defaults to 30 seconds.
+	Timeout time.Duration
+	// Retries is the number of times a bare line ending is sent to wake the console up when a step times out.
+	Retries int
+}
+
+// StepError is returned when a step of a playbook fails.
+type StepError struct {
+	Playbook string
+	Step     int
+	Name     string
+	Err      error
+}
+
+func (e *StepError) Error() string {
+	name := e.Name
+	if name == "" {
+		name = "#" + strconv.Itoa(e.Step)
+	}
+	return "Playbook " + e.Playbook + " failed at step " + name + ": " + e.Err.Error()
+}
+
+func (e *StepError) Unwrap() error {
+	return e.Err
+}
+
+// maxReactions bounds the number of reactions per step, so that a prompt matching forever cannot loop.
+const maxReactions = 1000
+
+// Run plays the playbook on the console.
+// The console output is copied to transcript when it is not nil.
+func (p *Playbook) Run(ctx context.Context, conn *Conn, transcript io.Writer) error {
+	if transcript == nil {
+		transcript = io.Discard
+	}
+	defaultTimeout := p.Timeout
+	if defaultTimeout == 0 {
+		defaultTimeout = 30 * time.Second
+	}
+	for i, step := range p.Steps {
+		err := p.runStep(ctx, conn, transcript, step, defaultTimeout)
+		if err != nil {
+			transcript.Write([]byte(conn.Drain()))
+			return &StepError{Playbook: p.Name, Step: i, Name: step.Name, Err: err}
+		}
+	}
+	return nil
+}
+
+func (p *Playbook) runStep(ctx context.Context, conn *Conn, transcript io.Writer, step Step, defaultTimeout time.Duration) error {
+	if step.Expect != nil {
+		timeout := step.Timeout
+		if timeout == 0 {
+			timeout = defaultTimeout
+		}
+		retries := step.Retries
+		if retries == 0 {
+			retries = p.Retries
+		}
+		reactionList := append(append([]Reaction{}, step.Reactions...), p.Reactions...)
+		patterns := []*regexp.Regexp{step.Expect}
+		for _, reaction := range reactionList {
+			patterns = append(patterns, reaction.Pattern)
+		}
+		matched := false
+		for reactions := 0; !matched; {
+			stepCtx, cancel := context.WithTimeout(ctx, timeout)
+			index, out, err := conn.ExpectAny(stepCtx, patterns...)
+			cancel()
+			transcript.Write([]byte(out))
+			switch {
+			case err != nil && ctx.Err() == nil && errors.Is(err, context.DeadlineExceeded):
+				if retries > 0 {
+					retries--
+					if err := conn.SendLine(ctx, ""); err != nil {
+						return err
+					}
+					continue
+				}
+				if step.Optional {
+					return nil
+				}
+				return errors.New("Timed out waiting for " + step.Expect.String())
+			case err != nil:
+				return err
+			case index == 0:
+				matched = true
+			default:
+				reactions++
+				if reactions > maxReactions {
+					return errors.New("Too many reactions")
+				}
+				reaction := reactionList[index-1]
+				if reaction.Raw {
+					_, err = conn.Write([]byte(reaction.Send))
+				} else {
+					err = conn.SendLine(ctx, reaction.Send)
+				}
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if step.NoSend {
+		return nil
+	}
+	for _, line := range strings.Split(step.Send, "\n") {
+		if err := conn.SendLine(ctx, strings.TrimRight(line, "\r")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LabOptions configures RunLab.
+type LabOptions struct {
+	// Playbook returns the playbook of a node, nodes with a nil playbook are skipped.
+	Playbook func(node evengsdk.Node) *Playbook
+	// TranscriptDir receives a "<node name>_<node id>.log" transcript per node when it is not empty.
+	TranscriptDir string
+	// Concurrency is the number of consoles driven at the same time, 0 means all of them.
+	Concurrency int
+}
+
+// NodeResult is the outcome of running a playbook on a node.
+type NodeResult struct {
+	NodeId     int
+	Name       string
+	Transcript string
+	Duration   time.Duration
+	Skipped    bool
+	Err        error
+}
+
+// RunLab runs a playbook on the console of every node in the specified path and reports the result of each node.
+// The path should be the full path to the lab file, including the extension (e.g. /path/to/labfile.unl).
+func RunLab(ctx context.Context, client *evengsdk.Client, path string, opts LabOptions) ([]NodeResult, error) {
+	if opts.Playbook == nil {
+		return nil, errors.New("No playbook")
+	}
+	nodes, err := client.Node.GetNodes(path)
+	if err != nil {
+		return nil, err
+	}
+	if opts.TranscriptDir != "" {
+		if err := os.MkdirAll(opts.TranscriptDir, 0o755); err != nil {
+			return nil, err
+		}
+	}
+	sorted := make([]evengsdk.Node, 0, len(nodes))
+	for _, node := range nodes {
+		sorted = append(sorted, node)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Id < sorted[j].Id })
+
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = max(len(sorted), 1)
+	}
+	sem := make(chan struct{}, concurrency)
+	results := make([]NodeResult, len(sorted))
+	var wg sync.WaitGroup
+	for i, node := range sorted {
+		results[i] = NodeResult{NodeId: node.Id, Name: node.Name}
+		playbook := opts.Playbook(node)
+		if playbook == nil {
+			results[i].Skipped = true
+			continue
+		}
+		wg.Add(1)
+		go func(node evengsdk.Node, result *NodeResult) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			runNode(ctx, node, playbook, opts.TranscriptDir, result)
+		}(node, &results[i])
+	}
+	wg.Wait()
+	return results, nil
+}
+
+func runNode(ctx context.Context, node evengsdk.Node, playbook *Playbook, dir string, result *NodeResult) {
+	start := time.Now()
+	defer func() { result.Duration = time.Since(start) }()
+	var transcript strings.Builder
+	var w io.Writer = &transcript
+	if dir != "" {
+		f, err := os.Create(filepath.Join(dir, transcriptName(&node)))
+		if err != nil {
+			result.Err = err
+			return
+		}
+		defer f.Close()
+		w = io.MultiWriter(&transcript, f)
+	}
+	defer func() { result.Transcript = transcript.String() }()
+	conn, err := Open(ctx, &node)
+	if err != nil {
+		result.Err = err
+		return
+	}
+	defer conn.Close()
+	result.Err = playbook.Run(ctx, conn, w)
+}
+
+// transcriptName returns the transcript file name of a node, its name with the characters that are not safe in file names replaced.
+// Node names are not unique in a lab, and sanitized names may collide, so the name also carries the node id.
+func transcriptName(node *evengsdk.Node) string {
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ':' || r == 0 {
+			return '_'
+		}
+		return r
+	}, node.Name) + "_" + strconv.Itoa(node.Id) + ".log"
+}
@@ -0,0 +1,155 @@
+package console
+
+import (
+	"errors"
+	"github.com/CorentinPtrl/evengsdk"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// ProfileOptions are the settings of the built-in profiles.
+type ProfileOptions struct {
+	// Username and Password answer login prompts.
+	Username string
+	Password string
+	// EnableSecret answers the enable password prompt of Cisco devices.
+	EnableSecret string
+	// Config is pasted in configuration mode (or typed at the prompt for vpcs and linux), one command per line.
+	Config string
+	// Save writes the configuration to the startup-config once the Config is applied.
+	Save bool
+}
+
+var (
+	iosExecPrompt   = regexp.MustCompile(`(?m)^[\w.\-]+[>#]\s*$`)
+	iosEnablePrompt = regexp.MustCompile(`(?m)^[\w.\-]+#\s*$`)
+	iosConfigPrompt = regexp.MustCompile(`(?m)^[\w.\-]+\(config[^)]*\)#\s*$`)
+	vpcsPrompt      = regexp.MustCompile(`(?m)^[\w.\-]+>\s*$`)
+	linuxPrompt     = regexp.MustCompile(`(?m)[$#]\s*$`)
+	passwordPrompt  = regexp.MustCompile(`(?i)password:\s*$`)
+)
+
+var profiles = map[string]func(ProfileOptions) *Playbook{
+	"vios":   iosProfile("vios"),
+	"viosl2": iosProfile("viosl2"),
+	"iol":    iosProfile("iol"),
+	"vpcs":   vpcsProfile,
+	"linux":  linuxProfile,
+}
+
+// Profiles returns the names of the built-in profiles.
+func Profiles() []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Profile returns the built-in bootstrap playbook of a node template (vios, viosl2, iol, vpcs or linux).
+func Profile(template string, opts ProfileOptions) (*Playbook, error) {
+	profile, ok := profiles[template]
+	if !ok {
+		return nil, errors.New("No console profile for template " + template)
+	}
+	return profile(opts), nil
+}
+
+// NodeProfile returns a LabOptions.Playbook function using the built-in profile of each node template.
+// Nodes without a built-in profile are skipped.
+func NodeProfile(opts func(node evengsdk.Node) ProfileOptions) func(node evengsdk.Node) *Playbook {
+	return func(node evengsdk.Node) *Playbook {
+		playbook, err := Profile(node.Template, opts(node))
+		if err != nil {
+			return nil
+		}
+		return playbook
+	}
+}
+
+func iosProfile(name string) func(ProfileOptions) *Playbook {
+	return func(opts ProfileOptions) *Playbook {
+		p := &Playbook{
+			Name:    name,
+			Timeout: 5 * time.Minute,
+			Retries: 10,
+			Reactions: []Reaction{
+				{Pattern: regexp.MustCompile(`initial configuration dialog\? \[yes/no\]:\s*$`), Send: "no"},
+				{Pattern: regexp.MustCompile(`terminate autoinstall\? \[yes\]:\s*$`), Send: "yes"},
+				{Pattern: regexp.MustCompile(`Press RETURN to get started`), Send: ""},
+				{Pattern: regexp.MustCompile(`(?i)username:\s*$`), Send: opts.Username},
+				{Pattern: regexp.MustCompile(`(?m)^\s*--More--\s*`), Send: " ", Raw: true},
+			},
+		}
+		p.Steps = append(p.Steps,
+			Step{
+				Name:      "login",
+				Expect:    iosExecPrompt,
+				Send:      "enable",
+				Reactions: []Reaction{{Pattern: passwordPrompt, Send: opts.Password}},
+			},
+			Step{
+				Name:      "enable",
+				Expect:    iosEnablePrompt,
+				Send:      "terminal length 0",
+				Timeout:   30 * time.Second,
+				Reactions: []Reaction{{Pattern: passwordPrompt, Send: opts.EnableSecret}},
+			},
+		)
+		if opts.Config != "" {
+			p.Steps = append(p.Steps,
+				Step{Name: "configure", Expect: iosEnablePrompt, Send: "configure terminal", Timeout: 30 * time.Second},
+				Step{Name: "config", Expect: iosConfigPrompt, Send: opts.Config, Timeout: 30 * time.Second},
+				Step{Name: "end", Expect: iosConfigPrompt, Send: "end", Timeout: 30 * time.Second},
+			)
+		}
+		if opts.Save {
+			p.Steps = append(p.Steps,
+				Step{Name: "save", Expect: iosEnablePrompt, Send: "write memory", Timeout: 30 * time.Second},
+			)
+		}
+		p.Steps = append(p.Steps, Step{Name: "done", Expect: iosEnablePrompt, NoSend: true, Timeout: 2 * time.Minute})
+		return p
+	}
+}
+
+func vpcsProfile(opts ProfileOptions) *Playbook {
+	p := &Playbook{
+		Name:    "vpcs",
+		Timeout: 30 * time.Second,
+		Retries: 5,
+		Steps:   []Step{{Name: "prompt", Expect: vpcsPrompt, NoSend: true}},
+	}
+	if opts.Config != "" {
+		p.Steps[0].NoSend = false
+		p.Steps[0].Send = opts.Config
+	}
+	if opts.Save {
+		p.Steps = append(p.Steps, Step{Name: "save", Expect: vpcsPrompt, Send: "save"})
+	}
+	if opts.Config != "" || opts.Save {
+		p.Steps = append(p.Steps, Step{Name: "done", Expect: vpcsPrompt, NoSend: true})
+	}
+	return p
+}
+
+func linuxProfile(opts ProfileOptions) *Playbook {
+	p := &Playbook{
+		Name:    "linux",
+		Timeout: 5 * time.Minute,
+		Retries: 10,
+		Reactions: []Reaction{
+			{Pattern: regexp.MustCompile(`login:\s*$`), Send: opts.Username},
+			{Pattern: passwordPrompt, Send: opts.Password},
+		},
+		Steps: []Step{{Name: "login", Expect: linuxPrompt, NoSend: true}},
+	}
+	if opts.Config != "" {
+		p.Steps[0].NoSend = false
+		p.Steps[0].Send = opts.Config
+		p.Steps = append(p.Steps, Step{Name: "done", Expect: linuxPrompt, NoSend: true, Timeout: 30 * time.Second})
+	}
+	return p
+}
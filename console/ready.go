@@ -0,0 +1,75 @@
+package console
+
+import (
+	"context"
+	"errors"
+	"github.com/CorentinPtrl/evengsdk"
+	"regexp"
+	"time"
+)
+
+var _ evengsdk.ReadyStrategy = PromptReady{}
+
+// PromptReady is an evengsdk.ReadyStrategy waiting for a prompt on the node console.
+// A bare line ending is sent every Interval to wake the console up, and the console is dialed again when it drops.
+type PromptReady struct {
+	Pattern *regexp.Regexp
+	// Interval defaults to 5 seconds.
+	Interval time.Duration
+	// Playbook is played once the prompt matched, to log in or answer the first boot dialogs.
+	Playbook *Playbook
+}
+
+func (r PromptReady) WaitReady(ctx context.Context, s *evengsdk.NodeService, path string, node *evengsdk.Node) error {
+	if r.Pattern == nil {
+		return errors.New("No prompt pattern")
+	}
+	interval := r.Interval
+	if interval == 0 {
+		interval = 5 * time.Second
+	}
+	for {
+		err := r.wait(ctx, node, interval)
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		var stepErr *StepError
+		if errors.As(err, &stepErr) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+func (r PromptReady) wait(ctx context.Context, node *evengsdk.Node, interval time.Duration) error {
+	conn, err := Open(ctx, node)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	for {
+		waitCtx, cancel := context.WithTimeout(ctx, interval)
+		_, err = conn.Expect(waitCtx, r.Pattern)
+		cancel()
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, context.DeadlineExceeded) || ctx.Err() != nil {
+			return err
+		}
+		if err = conn.SendLine(ctx, ""); err != nil {
+			return err
+		}
+	}
+	if r.Playbook != nil {
+		return r.Playbook.Run(ctx, conn, nil)
+	}
+	return nil
+}
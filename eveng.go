@@ -9,6 +9,7 @@ import (
 	"github.com/hashicorp/go-cleanhttp"
 	"github.com/hashicorp/go-retryablehttp"
 	"io"
+	"mime/multipart"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -57,6 +58,8 @@ type Client struct {
 	Node                      *NodeService
 	Folder                    *FolderService
 	Network                   *NetworkService
+	Link                      *LinkService
+	Icon                      *IconService
 	lock                      *sync.Mutex
 }
 
@@ -99,6 +102,8 @@ func newClient() (*Client, error) {
 	c.Node = &NodeService{client: c}
 	c.Folder = &FolderService{client: c}
 	c.Network = &NetworkService{client: c}
+	c.Link = &LinkService{client: c}
+	c.Icon = &IconService{client: c}
 	c.lock = &sync.Mutex{}
 	return c, nil
 }
@@ -180,6 +185,33 @@ func (c *Client) GetStatus() (map[string]any, error) {
 }
 
 func (c *Client) Do(ctx context.Context, method, url string, body []byte) (*Response, *http.Response, error) {
+	return c.do(ctx, method, url, body, "application/json")
+}
+
+// upload sends fields and a file as a multipart form, for the API endpoints taking files such as lab pictures.
+func (c *Client) upload(ctx context.Context, url string, fields map[string]string, field, filename string, file []byte) (*Response, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	for key, value := range fields {
+		if err := form.WriteField(key, value); err != nil {
+			return nil, err
+		}
+	}
+	part, err := form.CreateFormFile(field, filename)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := part.Write(file); err != nil {
+		return nil, err
+	}
+	if err := form.Close(); err != nil {
+		return nil, err
+	}
+	eve, _, err := c.do(ctx, "POST", url, body.Bytes(), form.FormDataContentType())
+	return eve, err
+}
+
+func (c *Client) do(ctx context.Context, method, url string, body []byte, contentType string) (*Response, *http.Response, error) {
 	c.lock.Lock()
 	defer c.lock.Unlock()
 	req, err := retryablehttp.NewRequest(method, c.baseURL.String()+url, bytes.NewBuffer(body))
@@ -190,7 +222,7 @@ func (c *Client) Do(ctx context.Context, method, url string, body []byte) (*Resp
 	if c.cookie != nil {
 		req.AddCookie(c.cookie)
 	}
-	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Type", contentType)
 	if ctx != nil {
 		req = req.WithContext(ctx)
 	}
@@ -218,6 +250,35 @@ func (c *Client) Do(ctx context.Context, method, url string, body []byte) (*Resp
 	return &response, resp, nil
 }
 
+// download writes the body of a non API resource, such as an image, to w and returns its content type.
+// The request is sent without the retries of the API requests, which expect a JSON body.
+func (c *Client) download(ctx context.Context, url string, w io.Writer) (string, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL.String()+url, nil)
+	if err != nil {
+		return "", err
+	}
+	if c.cookie != nil {
+		req.AddCookie(c.cookie)
+	}
+	resp, err := c.client.HTTPClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.New(resp.Status)
+	}
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return "", err
+	}
+	return resp.Header.Get("Content-Type"), nil
+}
+
 func (c *Client) BaseURL() *url.URL {
 	u := *c.baseURL
 	return &u
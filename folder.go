@@ -3,6 +3,8 @@ package evengsdk
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"sort"
 	"strings"
 )
 
@@ -87,3 +89,31 @@ func (s *FolderService) DeleteFolder(path string) error {
 	}
 	return nil
 }
+
+// GetLabs returns the paths of the labs in the specified folder and its subfolders, sorted.
+// The root path is "/". The walk stops once the context is done.
+func (s *FolderService) GetLabs(ctx context.Context, path string) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	folder, err := s.GetFolder(path)
+	if err != nil {
+		return nil, errors.New(path + ": " + err.Error())
+	}
+	var labs []string
+	for _, lab := range folder.Labs {
+		labs = append(labs, lab.Path)
+	}
+	for _, sub := range folder.Folders {
+		if sub.Name == ".." {
+			continue
+		}
+		found, err := s.GetLabs(ctx, sub.Path)
+		if err != nil {
+			return nil, err
+		}
+		labs = append(labs, found...)
+	}
+	sort.Strings(labs)
+	return labs, nil
+}
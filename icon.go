@@ -0,0 +1,118 @@
+package evengsdk
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type IconService struct {
+	client *Client
+	lock   sync.Mutex
+	// icons caches the result of GetIcons.
+	icons []Icon
+	// networkIcons caches the network icons found on the server by ValidateNetwork.
+	networkIcons map[string]bool
+}
+
+// Icon is an image available for nodes and networks, Name is the value of their Icon field (e.g. Router.png).
+type Icon struct {
+	Name  string
+	Label string
+}
+
+// GetIcons returns the icons available on the server, sorted by name.
+func (s *IconService) GetIcons() ([]Icon, error) {
+	eve, _, err := s.client.Do(context.Background(), "GET", "api/list/icons", nil)
+	if err != nil {
+		return nil, err
+	}
+	data, ok := eve.Data.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("Unexpected icon list")
+	}
+	icons := make([]Icon, 0, len(data))
+	for name, label := range data {
+		icon := Icon{Name: name, Label: topologyString(label)}
+		if icon.Label == "" {
+			icon.Label = strings.TrimSuffix(name, path.Ext(name))
+		}
+		icons = append(icons, icon)
+	}
+	sort.Slice(icons, func(i, j int) bool { return icons[i].Name < icons[j].Name })
+	s.lock.Lock()
+	s.icons = icons
+	s.lock.Unlock()
+	return icons, nil
+}
+
+// InvalidateCache discards the icons cached by GetIcons and ValidateNetwork, the next validation fetches them again.
+// Call it after icons are added to or removed from the server.
+func (s *IconService) InvalidateCache() {
+	s.lock.Lock()
+	s.icons = nil
+	s.networkIcons = nil
+	s.lock.Unlock()
+}
+
+// Validate returns an error when the icon is not available on the server. An empty icon is valid.
+// The list of icons is fetched once and then reused, until InvalidateCache is called.
+func (s *IconService) Validate(icon string) error {
+	if icon == "" {
+		return nil
+	}
+	s.lock.Lock()
+	icons := s.icons
+	s.lock.Unlock()
+	if icons == nil {
+		var err error
+		if icons, err = s.GetIcons(); err != nil {
+			return err
+		}
+	}
+	i := sort.Search(len(icons), func(i int) bool { return icons[i].Name >= icon })
+	if i < len(icons) && icons[i].Name == icon {
+		return nil
+	}
+	return errors.New("Unknown icon " + strconv.Quote(icon))
+}
+
+// ValidateNetwork is Validate for network icons.
+// The icon list of the server only holds node icons, so an icon missing from it is valid when the server serves its image,
+// as it does for the network icons EVE-NG ships (e.g. lan.png, 01-Cloud-Default.svg) and for custom ones.
+func (s *IconService) ValidateNetwork(icon string) error {
+	err := s.Validate(icon)
+	if err == nil || icon == "" || strings.ContainsAny(icon, "/\\") {
+		return err
+	}
+	s.lock.Lock()
+	found := s.networkIcons[icon]
+	s.lock.Unlock()
+	if found {
+		return nil
+	}
+	if _, derr := s.client.download(context.Background(), "images/icons/"+url.PathEscape(icon), io.Discard); derr != nil {
+		return err
+	}
+	s.lock.Lock()
+	if s.networkIcons == nil {
+		s.networkIcons = make(map[string]bool)
+	}
+	s.networkIcons[icon] = true
+	s.lock.Unlock()
+	return nil
+}
+
+// Download writes the image of the icon with the specified name to w and returns its content type (e.g. image/png).
+func (s *IconService) Download(ctx context.Context, name string, w io.Writer) (string, error) {
+	if name == "" || strings.ContainsAny(name, "/\\") {
+		return "", errors.New("Invalid icon name " + strconv.Quote(name))
+	}
+	return s.client.download(ctx, "images/icons/"+url.PathEscape(name), w)
+}
@@ -0,0 +1,196 @@
+package evengsdk
+
+import (
+	"errors"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// TemplateImages lists the images installed for a template.
+type TemplateImages struct {
+	Template    string
+	Description string
+	// Images is empty when no image is installed, the nodes of the template cannot start then.
+	Images []string
+	// Default is the image used by nodes that do not set one.
+	Default string
+}
+
+// ImageProblem is a node whose image is not installed.
+type ImageProblem struct {
+	NodeId   int
+	Name     string
+	Template string
+	Image    string
+	Reason   string
+}
+
+// ImageCheckError is returned by the image preflight checks, with one problem per node.
+type ImageCheckError struct {
+	Problems []ImageProblem
+}
+
+func (e *ImageCheckError) Error() string {
+	problems := make([]string, len(e.Problems))
+	for i, problem := range e.Problems {
+		problems[i] = problem.Name + ": " + problem.Reason
+	}
+	return "Missing images: " + strings.Join(problems, ", ")
+}
+
+// templateConcurrency is the number of templates fetched at the same time.
+const templateConcurrency = 8
+
+// getTemplates fetches the templates with the specified names concurrently.
+// Templates that could not be fetched are mapped to their error.
+func (s *NodeService) getTemplates(names []string) (map[string]*Template, map[string]error) {
+	templates := make(map[string]*Template, len(names))
+	errs := make(map[string]error)
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, templateConcurrency)
+	for _, name := range names {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			template, err := s.GetTemplate(name)
+			lock.Lock()
+			defer lock.Unlock()
+			if err != nil {
+				errs[name] = err
+				return
+			}
+			templates[name] = template
+		}(name)
+	}
+	wg.Wait()
+	return templates, errs
+}
+
+// joinTemplateErrors joins the errors returned by getTemplates, sorted by template name.
+func joinTemplateErrors(errs map[string]error) error {
+	failed := make([]string, 0, len(errs))
+	for name := range errs {
+		failed = append(failed, name)
+	}
+	sort.Strings(failed)
+	joined := make([]error, 0, len(errs))
+	for _, name := range failed {
+		joined = append(joined, errors.New(name+": "+errs[name].Error()))
+	}
+	return errors.Join(joined...)
+}
+
+// GetImages returns the images installed for every template using images, sorted by template name.
+// Templates without an image option, such as vpcs, are left out.
+// Templates that cannot be fetched are left out too, their errors are joined in the error returned with the other templates.
+func (s *NodeService) GetImages() ([]TemplateImages, error) {
+	list, err := s.GetTemplates()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(list))
+	for name := range list {
+		names = append(names, name)
+	}
+	templates, errs := s.getTemplates(names)
+	var images []TemplateImages
+	for name, template := range templates {
+		option, ok := template.Options["image"]
+		if !ok {
+			continue
+		}
+		description := template.Description
+		if description == "" {
+			description = list[name]
+		}
+		images = append(images, TemplateImages{Template: name, Description: description, Images: option.List, Default: option.Value})
+	}
+	sort.Slice(images, func(i, j int) bool { return images[i].Template < images[j].Template })
+	return images, joinTemplateErrors(errs)
+}
+
+// CheckImages verifies that the image of every node is installed for its template.
+// The nodes may come from a lab or be about to be created. The error is an *ImageCheckError.
+func (s *NodeService) CheckImages(nodes []Node) error {
+	var names []string
+	seen := make(map[string]bool)
+	for _, node := range nodes {
+		if !seen[node.Template] {
+			seen[node.Template] = true
+			names = append(names, node.Template)
+		}
+	}
+	templates, errs := s.getTemplates(names)
+	err := CheckNodeImages(nodes, templates)
+	var checkErr *ImageCheckError
+	if errors.As(err, &checkErr) {
+		for i, problem := range checkErr.Problems {
+			if fetchErr, ok := errs[problem.Template]; ok {
+				checkErr.Problems[i].Reason += " (" + fetchErr.Error() + ")"
+			}
+		}
+	}
+	return err
+}
+
+// CheckImages verifies that the image of every node in the specified path is installed for its template, see NodeService.CheckImages.
+// The path should be the full path to the lab file, including the extension (e.g. /path/to/labfile.unl).
+func (s *LabService) CheckImages(path string) error {
+	nodes, err := s.client.Node.GetNodes(path)
+	if err != nil {
+		return err
+	}
+	sorted := make([]Node, 0, len(nodes))
+	for _, node := range nodes {
+		sorted = append(sorted, node)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Id < sorted[j].Id })
+	return s.client.Node.CheckImages(sorted)
+}
+
+// CheckNodeImages verifies the image of every node against the templates, keyed by template name.
+// Nodes without an image use the template default. It returns an *ImageCheckError listing the nodes in the order given.
+func CheckNodeImages(nodes []Node, templates map[string]*Template) error {
+	var problems []ImageProblem
+	for _, node := range nodes {
+		problem := ImageProblem{NodeId: node.Id, Name: node.Name, Template: node.Template, Image: node.Image}
+		if problem.Name == "" {
+			problem.Name = "node " + strconv.Itoa(node.Id)
+		}
+		template, ok := templates[node.Template]
+		if !ok || template == nil {
+			problem.Reason = "Unknown template " + strconv.Quote(node.Template)
+			problems = append(problems, problem)
+			continue
+		}
+		option, ok := template.Options["image"]
+		if !ok {
+			continue
+		}
+		image := node.Image
+		if image == "" {
+			image = option.Value
+		}
+		switch {
+		case len(option.List) == 0:
+			problem.Reason = "No image installed for template " + node.Template
+		case image == "":
+			problem.Reason = "No image set and template " + node.Template + " has no default"
+		case !slices.Contains(option.List, image):
+			problem.Reason = "Image " + strconv.Quote(image) + " is not installed for template " + node.Template
+		default:
+			continue
+		}
+		problems = append(problems, problem)
+	}
+	if len(problems) > 0 {
+		return &ImageCheckError{Problems: problems}
+	}
+	return nil
+}
@@ -0,0 +1,221 @@
+package evengsdk
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// InventoryGroupBy selects how nodes are grouped in an Inventory.
+type InventoryGroupBy int
+
+const (
+	// GroupByTemplate groups nodes by their template (e.g. vios, viosl2).
+	GroupByTemplate InventoryGroupBy = iota
+	// GroupByType groups nodes by their type (e.g. qemu, iol).
+	GroupByType
+)
+
+type InventoryOptions struct {
+	GroupBy InventoryGroupBy
+	// ManagementInterface is the index of the ethernet interface exported as eve_mgmt_interface.
+	ManagementInterface int
+}
+
+// Inventory is an Ansible inventory built from the nodes of a lab.
+// Groups maps a group name to its host names, Hosts maps a host name to its variables.
+type Inventory struct {
+	Groups map[string][]string
+	Hosts  map[string]map[string]interface{}
+}
+
+// GetInventory returns an Ansible inventory of the nodes in the lab with the specified path.
+// The path should be the full path to the lab file, including the extension (e.g. /path/to/labfile.unl).
+func (s *LabService) GetInventory(path string, opts InventoryOptions) (*Inventory, error) {
+	nodes, err := s.client.Node.GetNodes(path)
+	if err != nil {
+		return nil, err
+	}
+	interfaces := make(map[int]*Interfaces, len(nodes))
+	for _, node := range nodes {
+		intfs, err := s.client.Node.GetNodeInterfaces(path, node.Id)
+		if err != nil {
+			return nil, err
+		}
+		interfaces[node.Id] = intfs
+	}
+	return NewInventory(nodes, interfaces, opts), nil
+}
+
+// NewInventory builds an Ansible inventory from nodes, as returned by GetNodes.
+// interfaces is optional and maps a node id to its interfaces, it is used to fill eve_mgmt_interface.
+// The console is exported as eve_console_host and eve_console_port, not as ansible_host and ansible_port:
+// consoles are telnet ports, which the default ssh connection of Ansible cannot use.
+func NewInventory(nodes map[string]Node, interfaces map[int]*Interfaces, opts InventoryOptions) *Inventory {
+	inv := &Inventory{
+		Groups: make(map[string][]string),
+		Hosts:  make(map[string]map[string]interface{}),
+	}
+	sorted := make([]Node, 0, len(nodes))
+	for _, node := range nodes {
+		sorted = append(sorted, node)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Id < sorted[j].Id })
+
+	// Every node name is reserved first, so that a suffixed duplicate never takes the name of another node.
+	reserved := make(map[string]bool, len(sorted))
+	for _, node := range sorted {
+		reserved[inventoryName(node.Name)] = true
+	}
+	taken := make(map[string]bool, len(sorted))
+	for _, node := range sorted {
+		host := inventoryName(node.Name)
+		if taken[host] || host == "" {
+			host += "_" + strconv.Itoa(node.Id)
+			for taken[host] || reserved[host] {
+				host += "_" + strconv.Itoa(node.Id)
+			}
+		}
+		taken[host] = true
+		vars := map[string]interface{}{
+			"eve_id":       node.Id,
+			"eve_uuid":     node.Uuid,
+			"eve_template": node.Template,
+			"eve_type":     string(node.Type),
+			"eve_image":    node.Image,
+		}
+		if node.Console != "" {
+			vars["eve_console_type"] = string(node.Console)
+		}
+		if consoleHost, consolePort, err := node.ConsoleAddress(); err == nil {
+			vars["eve_console_host"] = consoleHost
+			vars["eve_console_port"] = consolePort
+		}
+		if intfs, ok := interfaces[node.Id]; ok && intfs != nil {
+			if mgmt, ok := intfs.Ethernet[opts.ManagementInterface]; ok {
+				vars["eve_mgmt_interface"] = mgmt.Name
+			}
+		}
+		inv.Hosts[host] = vars
+
+		group := node.Template
+		if opts.GroupBy == GroupByType {
+			group = string(node.Type)
+		}
+		group = inventoryName(group)
+		if group == "" {
+			group = "ungrouped"
+		}
+		inv.Groups[group] = append(inv.Groups[group], host)
+	}
+	return inv
+}
+
+// inventoryName replaces the characters Ansible does not accept in group and host names.
+func inventoryName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || r == '-' || r == '.' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9') {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+func (inv *Inventory) groupNames() []string {
+	names := make([]string, 0, len(inv.Groups))
+	for name := range inv.Groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func sortedVarNames(vars map[string]interface{}) []string {
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// inventoryValue formats a variable for both YAML and INI inventories: ints are written as is, other values as double quoted strings.
+func inventoryValue(v interface{}) string {
+	switch t := v.(type) {
+	case int:
+		return strconv.Itoa(t)
+	case string:
+		return strconv.Quote(t)
+	}
+	return strconv.Quote(fmt.Sprint(v))
+}
+
+// WriteYAML writes the inventory in the Ansible YAML inventory format.
+func (inv *Inventory) WriteYAML(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "all:\n  children:\n")
+	for _, group := range inv.groupNames() {
+		fmt.Fprintf(bw, "    %s:\n      hosts:\n", group)
+		for _, host := range inv.Groups[group] {
+			fmt.Fprintf(bw, "        %s:\n", host)
+			vars := inv.Hosts[host]
+			for _, name := range sortedVarNames(vars) {
+				fmt.Fprintf(bw, "          %s: %s\n", name, inventoryValue(vars[name]))
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+// WriteINI writes the inventory in the Ansible INI inventory format.
+func (inv *Inventory) WriteINI(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for i, group := range inv.groupNames() {
+		if i > 0 {
+			fmt.Fprintln(bw)
+		}
+		fmt.Fprintf(bw, "[%s]\n", group)
+		for _, host := range inv.Groups[group] {
+			fmt.Fprint(bw, host)
+			vars := inv.Hosts[host]
+			for _, name := range sortedVarNames(vars) {
+				fmt.Fprintf(bw, " %s=%s", name, inventoryValue(vars[name]))
+			}
+			fmt.Fprintln(bw)
+		}
+	}
+	return bw.Flush()
+}
+
+// WriteJSON writes the inventory in the format expected from "--list" by Ansible dynamic inventory scripts.
+func (inv *Inventory) WriteJSON(w io.Writer) error {
+	out := map[string]interface{}{
+		"_meta": map[string]interface{}{"hostvars": inv.Hosts},
+		"all":   map[string]interface{}{"children": inv.groupNames()},
+	}
+	for group, hosts := range inv.Groups {
+		out[group] = map[string]interface{}{"hosts": hosts}
+	}
+	return json.NewEncoder(w).Encode(out)
+}
+
+// RunScript implements the Ansible dynamic inventory script protocol.
+// args are the command line arguments without the program name, either "--list" or "--host <name>".
+func (inv *Inventory) RunScript(args []string, w io.Writer) error {
+	switch {
+	case len(args) == 1 && args[0] == "--list":
+		return inv.WriteJSON(w)
+	case len(args) == 2 && args[0] == "--host":
+		vars, ok := inv.Hosts[args[1]]
+		if !ok {
+			vars = map[string]interface{}{}
+		}
+		return json.NewEncoder(w).Encode(vars)
+	}
+	return errors.New("Usage: --list | --host <name>")
+}
@@ -0,0 +1,157 @@
+package evengsdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// TextObject is a text, shape or other drawing of a lab. Data is kept as returned by EVE-NG, base64 encoded HTML.
+type TextObject struct {
+	Id   int    `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+	Data string `json:"data"`
+}
+
+// Picture is an image attached to a lab, Map is the HTML image map linking its areas to node consoles.
+type Picture struct {
+	Id     int    `json:"id"`
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	Map    string `json:"map,omitempty"`
+}
+
+// GetTextObjects returns all text objects in the specified path.
+// The path should be the full path to the lab file, including the extension (e.g. /path/to/labfile.unl).
+func (s *LabService) GetTextObjects(path string) (map[string]TextObject, error) {
+	name := path[strings.LastIndex(path, "/")+1:]
+	path = path[:strings.LastIndex(path, "/")+1]
+	eve, _, err := s.client.Do(context.Background(), "GET", "api/labs/"+path+url.QueryEscape(name)+"/textobjects", nil)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(eve.Data)
+	if err != nil {
+		return nil, err
+	}
+	objects := make(map[string]TextObject)
+	// Labs without text objects return an empty list instead of an object.
+	if bytes.HasPrefix(data, []byte("[")) {
+		return objects, nil
+	}
+	err = json.Unmarshal(data, &objects)
+	if err != nil {
+		return nil, err
+	}
+	return objects, nil
+}
+
+// CreateTextObject creates a new text object in the specified path.
+// The path should be the full path to the lab file, including the extension (e.g. /path/to/labfile.unl).
+// The Id field will be set to the id of the new text object.
+func (s *LabService) CreateTextObject(path string, object *TextObject) error {
+	name := path[strings.LastIndex(path, "/")+1:]
+	path = path[:strings.LastIndex(path, "/")+1]
+	body, err := json.Marshal(map[string]string{"name": object.Name, "type": object.Type, "data": object.Data})
+	if err != nil {
+		return err
+	}
+	eve, _, err := s.client.Do(context.Background(), "POST", "api/labs/"+path+url.QueryEscape(name)+"/textobjects", body)
+	if err != nil {
+		return err
+	}
+	if data, ok := eve.Data.(map[string]interface{}); ok {
+		object.Id = topologyInt(data["id"])
+	}
+	return nil
+}
+
+// GetPictures returns all pictures in the specified path, without their image map.
+// The path should be the full path to the lab file, including the extension (e.g. /path/to/labfile.unl).
+func (s *LabService) GetPictures(path string) (map[string]Picture, error) {
+	name := path[strings.LastIndex(path, "/")+1:]
+	path = path[:strings.LastIndex(path, "/")+1]
+	eve, _, err := s.client.Do(context.Background(), "GET", "api/labs/"+path+url.QueryEscape(name)+"/pictures", nil)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(eve.Data)
+	if err != nil {
+		return nil, err
+	}
+	pictures := make(map[string]Picture)
+	// Labs without pictures return an empty list instead of an object.
+	if bytes.HasPrefix(data, []byte("[")) {
+		return pictures, nil
+	}
+	err = json.Unmarshal(data, &pictures)
+	if err != nil {
+		return nil, err
+	}
+	return pictures, nil
+}
+
+// GetPicture returns the picture with the specified id in the specified path, with its image map.
+// The path should be the full path to the lab file, including the extension (e.g. /path/to/labfile.unl).
+func (s *LabService) GetPicture(path string, id int) (*Picture, error) {
+	name := path[strings.LastIndex(path, "/")+1:]
+	path = path[:strings.LastIndex(path, "/")+1]
+	eve, _, err := s.client.Do(context.Background(), "GET", "api/labs/"+path+url.QueryEscape(name)+"/pictures/"+strconv.Itoa(id), nil)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(eve.Data)
+	if err != nil {
+		return nil, err
+	}
+	var picture Picture
+	err = json.Unmarshal(data, &picture)
+	if err != nil {
+		return nil, err
+	}
+	picture.Id = id
+	return &picture, nil
+}
+
+// GetPictureData returns the image of the picture with the specified id in the specified path.
+// The path should be the full path to the lab file, including the extension (e.g. /path/to/labfile.unl).
+func (s *LabService) GetPictureData(path string, id int) ([]byte, error) {
+	name := path[strings.LastIndex(path, "/")+1:]
+	path = path[:strings.LastIndex(path, "/")+1]
+	var image bytes.Buffer
+	_, err := s.client.download(context.Background(), "api/labs/"+path+url.QueryEscape(name)+"/pictures/"+strconv.Itoa(id)+"/data", &image)
+	if err != nil {
+		return nil, err
+	}
+	return image.Bytes(), nil
+}
+
+// CreatePicture uploads a new picture in the specified path.
+// The path should be the full path to the lab file, including the extension (e.g. /path/to/labfile.unl).
+// Name and Map are taken from the picture, the Id field will be set to the id of the new picture.
+func (s *LabService) CreatePicture(path string, picture *Picture, image []byte) error {
+	name := path[strings.LastIndex(path, "/")+1:]
+	path = path[:strings.LastIndex(path, "/")+1]
+	filename := picture.Name
+	if ext := strings.TrimPrefix(picture.Type, "image/"); ext != "" && ext != picture.Type {
+		filename += "." + ext
+	}
+	fields := map[string]string{"name": picture.Name}
+	if picture.Map != "" {
+		fields["map"] = picture.Map
+	}
+	eve, err := s.client.upload(context.Background(), "api/labs/"+path+url.QueryEscape(name)+"/pictures", fields, "file", filename, image)
+	if err != nil {
+		return err
+	}
+	if data, ok := eve.Data.(map[string]interface{}); ok {
+		picture.Id = topologyInt(data["id"])
+	}
+	return nil
+}
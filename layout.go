@@ -0,0 +1,352 @@
+package evengsdk
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LayoutAlgorithm selects how ComputeLayout places nodes and networks.
+type LayoutAlgorithm int
+
+const (
+	// LayoutGrid places vertices on a grid, nodes first, in id order.
+	LayoutGrid LayoutAlgorithm = iota
+	// LayoutCircular places vertices on a circle.
+	LayoutCircular
+	// LayoutForce runs a force-directed (Fruchterman-Reingold) simulation, connected vertices end up close to each other.
+	LayoutForce
+	// LayoutHierarchical places vertices in tiers by their distance from the Roots.
+	LayoutHierarchical
+)
+
+type LayoutOptions struct {
+	Algorithm LayoutAlgorithm
+	// Width and Height constrain the canvas, every position is kept between Margin and Width-Margin (Height-Margin).
+	// They default to 1600 by 1000.
+	Width  int
+	Height int
+	// Margin defaults to 50.
+	Margin int
+	// Spacing is the distance between grid cells and tiers. It defaults to 150 and shrinks to fit the canvas.
+	Spacing int
+	// Iterations of the force-directed simulation, defaults to 300.
+	Iterations int
+	// Roots are the ids of the nodes on the first tier of a hierarchical layout.
+	// The lowest node id of each connected group is used when it is empty.
+	Roots []int
+	// PinnedNodes and PinnedNetworks keep their current position, the other vertices are laid out around them.
+	PinnedNodes    []int
+	PinnedNetworks []int
+}
+
+// LayoutPosition is the Left/Top position of a node or network.
+type LayoutPosition struct {
+	Left int
+	Top  int
+}
+
+// Layout holds the computed positions, keyed by node and network id.
+type Layout struct {
+	Nodes    map[int]LayoutPosition
+	Networks map[int]LayoutPosition
+}
+
+type layoutVertex struct {
+	id     string
+	x, y   float64
+	pinned bool
+}
+
+// ApplyLayout computes a layout of the lab with the specified path and updates the nodes and networks that moved.
+// The path should be the full path to the lab file, including the extension (e.g. /path/to/labfile.unl).
+func (s *LabService) ApplyLayout(path string, opts LayoutOptions) (*Layout, error) {
+	topology, err := s.GetLabTopology(path)
+	if err != nil {
+		return nil, err
+	}
+	layout, err := ComputeLayout(topology, opts)
+	if err != nil {
+		return nil, err
+	}
+	for _, node := range topology.Nodes {
+		pos, ok := layout.Nodes[node.Id]
+		if !ok || (pos.Left == node.Left && pos.Top == node.Top) {
+			continue
+		}
+		// Only the position is sent, the nodes of the topology lack the settings UpdateNode would send.
+		err = s.client.Node.updateNodeFields(path, node.Id, map[string]interface{}{"left": pos.Left, "top": pos.Top})
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, network := range topology.Networks {
+		pos, ok := layout.Networks[network.Id]
+		if !ok || (pos.Left == network.Left && pos.Top == network.Top) {
+			continue
+		}
+		network.Left, network.Top = pos.Left, pos.Top
+		err = s.client.Network.UpdateNetwork(path, &network)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return layout, nil
+}
+
+// ComputeLayout computes the position of every node and visible network of the topology.
+func ComputeLayout(t *Topology, opts LayoutOptions) (*Layout, error) {
+	if opts.Width == 0 {
+		opts.Width = 1600
+	}
+	if opts.Height == 0 {
+		opts.Height = 1000
+	}
+	if opts.Margin == 0 {
+		opts.Margin = 50
+	}
+	if opts.Spacing == 0 {
+		opts.Spacing = 150
+	}
+	if opts.Iterations == 0 {
+		opts.Iterations = 300
+	}
+	if opts.Width <= 2*opts.Margin || opts.Height <= 2*opts.Margin {
+		return nil, errors.New("Canvas is smaller than its margins")
+	}
+
+	pinned := make(map[string]bool)
+	for _, id := range opts.PinnedNodes {
+		pinned["node"+strconv.Itoa(id)] = true
+	}
+	for _, id := range opts.PinnedNetworks {
+		pinned["network"+strconv.Itoa(id)] = true
+	}
+	// Hidden point-to-point bridges are edges between their nodes, visible networks are vertices placed like the nodes.
+	g := newRenderGraph(t, RenderOptions{})
+	vertices := make([]*layoutVertex, 0, len(g.vertices))
+	index := make(map[string]*layoutVertex, len(g.vertices))
+	for _, v := range g.vertices {
+		lv := &layoutVertex{id: v.id, x: float64(v.left), y: float64(v.top), pinned: pinned[v.id]}
+		vertices = append(vertices, lv)
+		index[v.id] = lv
+	}
+	adjacency := make(map[string][]string)
+	for _, e := range g.edges {
+		if index[e.from] == nil || index[e.to] == nil {
+			continue
+		}
+		adjacency[e.from] = append(adjacency[e.from], e.to)
+		adjacency[e.to] = append(adjacency[e.to], e.from)
+	}
+
+	switch opts.Algorithm {
+	case LayoutGrid:
+		layoutGrid(vertices, opts)
+	case LayoutCircular:
+		layoutCircular(vertices, opts)
+	case LayoutForce:
+		layoutForce(vertices, adjacency, opts)
+	case LayoutHierarchical:
+		layoutHierarchical(vertices, index, adjacency, opts)
+	default:
+		return nil, errors.New("Unknown layout algorithm")
+	}
+
+	layout := &Layout{Nodes: make(map[int]LayoutPosition), Networks: make(map[int]LayoutPosition)}
+	for _, v := range vertices {
+		pos := LayoutPosition{Left: int(math.Round(v.x)), Top: int(math.Round(v.y))}
+		if !v.pinned {
+			pos.Left = min(max(pos.Left, opts.Margin), opts.Width-opts.Margin)
+			pos.Top = min(max(pos.Top, opts.Margin), opts.Height-opts.Margin)
+		}
+		if strings.HasPrefix(v.id, "node") {
+			layout.Nodes[topologyId(v.id)] = pos
+		} else {
+			layout.Networks[topologyId(v.id)] = pos
+		}
+	}
+	return layout, nil
+}
+
+func layoutFree(vertices []*layoutVertex) []*layoutVertex {
+	var free []*layoutVertex
+	for _, v := range vertices {
+		if !v.pinned {
+			free = append(free, v)
+		}
+	}
+	return free
+}
+
+// layoutOccupied reports whether a pinned vertex is closer than distance to x, y.
+func layoutOccupied(vertices []*layoutVertex, x, y, distance float64) bool {
+	for _, v := range vertices {
+		if v.pinned && math.Hypot(v.x-x, v.y-y) < distance {
+			return true
+		}
+	}
+	return false
+}
+
+func layoutGrid(vertices []*layoutVertex, opts LayoutOptions) {
+	free := layoutFree(vertices)
+	if len(free) == 0 {
+		return
+	}
+	width := float64(opts.Width - 2*opts.Margin)
+	height := float64(opts.Height - 2*opts.Margin)
+	spacing := float64(opts.Spacing)
+	// Shrink the cells until the grid fits the canvas, pinned vertices may take a cell each.
+	for spacing > 1 && (math.Floor(width/spacing)+1)*(math.Floor(height/spacing)+1) < float64(len(vertices)) {
+		spacing *= 0.9
+	}
+	cols := int(math.Floor(width/spacing)) + 1
+	cell := 0
+	for _, v := range free {
+		for {
+			x := float64(opts.Margin) + float64(cell%cols)*spacing
+			y := float64(opts.Margin) + float64(cell/cols)*spacing
+			cell++
+			if !layoutOccupied(vertices, x, y, spacing/2) || y > float64(opts.Height-opts.Margin) {
+				v.x, v.y = x, y
+				break
+			}
+		}
+	}
+}
+
+func layoutCircular(vertices []*layoutVertex, opts LayoutOptions) {
+	free := layoutFree(vertices)
+	cx := float64(opts.Width) / 2
+	cy := float64(opts.Height) / 2
+	radius := math.Min(cx, cy) - float64(opts.Margin)
+	for i, v := range free {
+		angle := 2*math.Pi*float64(i)/float64(len(free)) - math.Pi/2
+		v.x = cx + radius*math.Cos(angle)
+		v.y = cy + radius*math.Sin(angle)
+	}
+}
+
+func layoutForce(vertices []*layoutVertex, adjacency map[string][]string, opts LayoutOptions) {
+	if len(layoutFree(vertices)) == 0 {
+		return
+	}
+	// Start from the circular layout, so that the result is deterministic.
+	layoutCircular(vertices, opts)
+	minX, maxX := float64(opts.Margin), float64(opts.Width-opts.Margin)
+	minY, maxY := float64(opts.Margin), float64(opts.Height-opts.Margin)
+	k := math.Sqrt((maxX - minX) * (maxY - minY) / float64(len(vertices)))
+	temperature := (maxX - minX) / 10
+	index := make(map[string]int, len(vertices))
+	for i, v := range vertices {
+		index[v.id] = i
+	}
+	dx := make([]float64, len(vertices))
+	dy := make([]float64, len(vertices))
+	for iter := 0; iter < opts.Iterations; iter++ {
+		for i := range dx {
+			dx[i], dy[i] = 0, 0
+		}
+		for i, a := range vertices {
+			for j := i + 1; j < len(vertices); j++ {
+				b := vertices[j]
+				ddx, ddy := a.x-b.x, a.y-b.y
+				dist := math.Max(math.Hypot(ddx, ddy), 0.01)
+				force := k * k / dist
+				dx[i] += ddx / dist * force
+				dy[i] += ddy / dist * force
+				dx[j] -= ddx / dist * force
+				dy[j] -= ddy / dist * force
+			}
+		}
+		for i, a := range vertices {
+			for _, id := range adjacency[a.id] {
+				j := index[id]
+				if j <= i {
+					continue
+				}
+				b := vertices[j]
+				ddx, ddy := a.x-b.x, a.y-b.y
+				dist := math.Max(math.Hypot(ddx, ddy), 0.01)
+				force := dist * dist / k
+				dx[i] -= ddx / dist * force
+				dy[i] -= ddy / dist * force
+				dx[j] += ddx / dist * force
+				dy[j] += ddy / dist * force
+			}
+		}
+		for i, v := range vertices {
+			if v.pinned {
+				continue
+			}
+			dist := math.Max(math.Hypot(dx[i], dy[i]), 0.01)
+			step := math.Min(dist, temperature)
+			v.x = math.Min(math.Max(v.x+dx[i]/dist*step, minX), maxX)
+			v.y = math.Min(math.Max(v.y+dy[i]/dist*step, minY), maxY)
+		}
+		temperature *= 1 - 1/float64(opts.Iterations)
+	}
+}
+
+func layoutHierarchical(vertices []*layoutVertex, index map[string]*layoutVertex, adjacency map[string][]string, opts LayoutOptions) {
+	tiers := make(map[string]int)
+	var queue []string
+	for _, id := range opts.Roots {
+		if _, ok := index["node"+strconv.Itoa(id)]; ok {
+			tiers["node"+strconv.Itoa(id)] = 0
+			queue = append(queue, "node"+strconv.Itoa(id))
+		}
+	}
+	bfs := func() {
+		for len(queue) > 0 {
+			id := queue[0]
+			queue = queue[1:]
+			for _, next := range adjacency[id] {
+				if _, ok := tiers[next]; !ok {
+					tiers[next] = tiers[id] + 1
+					queue = append(queue, next)
+				}
+			}
+		}
+	}
+	bfs()
+	// Vertices are sorted nodes first, so each remaining group starts from its lowest node id.
+	for _, v := range vertices {
+		if _, ok := tiers[v.id]; !ok {
+			tiers[v.id] = 0
+			queue = append(queue, v.id)
+			bfs()
+		}
+	}
+
+	rows := make(map[int][]*layoutVertex)
+	depth := 0
+	for _, v := range vertices {
+		if v.pinned {
+			continue
+		}
+		rows[tiers[v.id]] = append(rows[tiers[v.id]], v)
+		depth = max(depth, tiers[v.id])
+	}
+	height := float64(opts.Height - 2*opts.Margin)
+	width := float64(opts.Width - 2*opts.Margin)
+	spacing := float64(opts.Spacing)
+	if depth > 0 {
+		spacing = math.Min(spacing, height/float64(depth))
+	}
+	levels := make([]int, 0, len(rows))
+	for level := range rows {
+		levels = append(levels, level)
+	}
+	sort.Ints(levels)
+	for _, level := range levels {
+		row := rows[level]
+		for i, v := range row {
+			v.x = float64(opts.Margin) + width*(float64(i)+0.5)/float64(len(row))
+			v.y = float64(opts.Margin) + float64(level)*spacing
+		}
+	}
+}
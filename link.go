@@ -0,0 +1,267 @@
+package evengsdk
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LinkService wires node interfaces together, hiding the networks EVE-NG needs to do so.
+type LinkService struct {
+	client *Client
+}
+
+// Endpoint is a node interface named as "node:interface" (e.g. R1:Gi0/0).
+type Endpoint struct {
+	Node      string
+	Interface string
+}
+
+// Link connects a node interface to another node interface or to a network.
+type Link struct {
+	A Endpoint
+	// B is empty when the interface is attached to a network shared by more than two interfaces or visible in the lab.
+	B    Endpoint
+	Kind InterfaceKind
+	// NetworkId and Network are the network carrying an ethernet link, 0 and empty for serial links.
+	NetworkId int
+	Network   string
+	// Hidden is true when the network is a hidden point-to-point bridge.
+	Hidden bool
+}
+
+// ParseEndpoint parses an endpoint written as "node:interface".
+func ParseEndpoint(endpoint string) (Endpoint, error) {
+	node, intf, ok := strings.Cut(endpoint, ":")
+	if !ok || node == "" || intf == "" {
+		return Endpoint{}, errors.New("Invalid endpoint " + strconv.Quote(endpoint) + ", expected node:interface")
+	}
+	return Endpoint{Node: node, Interface: intf}, nil
+}
+
+func (e Endpoint) String() string {
+	return e.Node + ":" + e.Interface
+}
+
+func (l Link) String() string {
+	if l.B == (Endpoint{}) {
+		return l.A.String() + " - " + l.Network
+	}
+	return l.A.String() + " - " + l.B.String()
+}
+
+// linkEnd is an endpoint resolved against the lab.
+type linkEnd struct {
+	endpoint Endpoint
+	node     Node
+	ref      InterfaceRef
+	intf     Interface
+}
+
+// resolve finds the nodes and interfaces of the endpoints in the specified path.
+func (s *LinkService) resolve(path string, endpoints ...string) ([]linkEnd, error) {
+	nodes, err := s.client.Node.GetNodes(path)
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string][]Node)
+	for _, node := range nodes {
+		byName[node.Name] = append(byName[node.Name], node)
+	}
+	ends := make([]linkEnd, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		e, err := ParseEndpoint(endpoint)
+		if err != nil {
+			return nil, err
+		}
+		switch len(byName[e.Node]) {
+		case 0:
+			return nil, errors.New("Node " + e.Node + " not found")
+		case 1:
+		default:
+			return nil, errors.New("Node name " + e.Node + " is not unique")
+		}
+		node := byName[e.Node][0]
+		interfaces, err := s.client.Node.GetNodeInterfaces(path, node.Id)
+		if err != nil {
+			return nil, err
+		}
+		ref, intf, ok := interfaces.Find(e.Interface)
+		if !ok {
+			return nil, errors.New("Interface " + e.String() + " not found")
+		}
+		ends = append(ends, linkEnd{endpoint: e, node: node, ref: ref, intf: intf})
+	}
+	if len(ends) == 2 && ends[0].node.Id == ends[1].node.Id && ends[0].ref.Index == ends[1].ref.Index {
+		return nil, errors.New("Cannot connect " + ends[0].endpoint.String() + " to itself")
+	}
+	return ends, nil
+}
+
+// Connect links two node interfaces, written as "node:interface" (e.g. R1:Gi0/0), in the specified path.
+// The path should be the full path to the lab file, including the extension (e.g. /path/to/labfile.unl).
+// Ethernet interfaces are attached to a new hidden bridge, serial interfaces are connected directly.
+// Both interfaces should be free. When a step fails, the changes already made are rolled back.
+func (s *LinkService) Connect(path string, a string, b string) (*Link, error) {
+	ends, err := s.resolve(path, a, b)
+	if err != nil {
+		return nil, err
+	}
+	for _, end := range ends {
+		if end.intf.NetworkId != 0 || end.intf.RemoteId != 0 {
+			return nil, errors.New("Interface " + end.endpoint.String() + " is already connected")
+		}
+	}
+	if ends[0].ref.Kind != ends[1].ref.Kind {
+		return nil, errors.New("Cannot connect " + string(ends[0].ref.Kind) + " interface " + ends[0].endpoint.String() + " to " + string(ends[1].ref.Kind) + " interface " + ends[1].endpoint.String())
+	}
+	link := &Link{A: ends[0].endpoint, B: ends[1].endpoint, Kind: ends[0].ref.Kind}
+	if link.Kind == InterfaceSerial {
+		err = s.client.Node.ConnectSerial(path, ends[0].node.Id, ends[0].ref.Index, ends[1].node.Id, ends[1].ref.Index)
+		if err != nil {
+			return nil, err
+		}
+		return link, nil
+	}
+
+	network := &Network{
+		Name:       ends[0].endpoint.Node + "_" + ends[0].endpoint.Interface + "-" + ends[1].endpoint.Node + "_" + ends[1].endpoint.Interface,
+		Type:       NetworkBridge,
+		Left:       (ends[0].node.Left + ends[1].node.Left) / 2,
+		Top:        (ends[0].node.Top + ends[1].node.Top) / 2,
+		Visibility: "0",
+	}
+	err = s.client.Network.CreateNetwork(path, network)
+	if err != nil {
+		return nil, err
+	}
+	var attached []linkEnd
+	rollback := func(err error) (*Link, error) {
+		errs := []error{err}
+		for _, end := range attached {
+			if e := s.client.Node.UpdateNodeInterface(path, end.node.Id, end.ref.Index, 0); e != nil {
+				errs = append(errs, errors.New("Rollback of "+end.endpoint.String()+": "+e.Error()))
+			}
+		}
+		if e := s.client.Network.DeleteNetwork(path, network.Id); e != nil {
+			errs = append(errs, errors.New("Rollback of network "+network.Name+": "+e.Error()))
+		}
+		return nil, errors.Join(errs...)
+	}
+	for _, end := range ends {
+		if err := s.client.Node.UpdateNodeInterface(path, end.node.Id, end.ref.Index, network.Id); err != nil {
+			return rollback(err)
+		}
+		attached = append(attached, end)
+	}
+	// EVE-NG makes a network visible again when interfaces are attached to it.
+	network.Visibility = "0"
+	if err := s.client.Network.UpdateNetwork(path, network); err != nil {
+		return rollback(err)
+	}
+	link.NetworkId = network.Id
+	link.Network = network.Name
+	link.Hidden = true
+	return link, nil
+}
+
+// Disconnect removes the link between two node interfaces, written as "node:interface", in the specified path.
+// The path should be the full path to the lab file, including the extension (e.g. /path/to/labfile.unl).
+// Hidden networks left without any interface are deleted.
+func (s *LinkService) Disconnect(path string, a string, b string) error {
+	ends, err := s.resolve(path, a, b)
+	if err != nil {
+		return err
+	}
+	if ends[0].ref.Kind == InterfaceSerial {
+		if ends[0].intf.RemoteId != ends[1].node.Id || ends[0].intf.RemoteIf != ends[1].ref.Index {
+			return errors.New(ends[0].endpoint.String() + " is not connected to " + ends[1].endpoint.String())
+		}
+		return s.client.Node.UpdateNodeInterface(path, ends[0].node.Id, ends[0].ref.Index, 0)
+	}
+	if ends[0].intf.NetworkId == 0 || ends[0].intf.NetworkId != ends[1].intf.NetworkId {
+		return errors.New(ends[0].endpoint.String() + " is not connected to " + ends[1].endpoint.String())
+	}
+	for _, end := range ends {
+		if err := s.client.Node.UpdateNodeInterface(path, end.node.Id, end.ref.Index, 0); err != nil {
+			return err
+		}
+	}
+	return s.cleanupNetwork(path, ends[0].intf.NetworkId)
+}
+
+// cleanupNetwork deletes the network with the specified id when it is hidden and no interface is attached to it anymore.
+func (s *LinkService) cleanupNetwork(path string, id int) error {
+	network, err := s.client.Network.GetNetwork(path, id)
+	if err != nil {
+		return err
+	}
+	if network.Count != 0 || network.Visibility != "0" {
+		return nil
+	}
+	return s.client.Network.DeleteNetwork(path, id)
+}
+
+// GetLinks returns the links of the lab with the specified path, see NodeLinks.
+// The path should be the full path to the lab file, including the extension (e.g. /path/to/labfile.unl).
+func (s *LinkService) GetLinks(path string) ([]Link, error) {
+	topology, err := s.client.Lab.GetLabTopology(path)
+	if err != nil {
+		return nil, err
+	}
+	return NodeLinks(topology), nil
+}
+
+// NodeLinks returns the links of a topology, sorted by endpoint.
+// Hidden networks with exactly two interfaces and serial links are returned as links between two endpoints,
+// every other interface is returned as a link to its network.
+func NodeLinks(t *Topology) []Link {
+	name := func(endpoint string) string {
+		if node, ok := t.Nodes[strconv.Itoa(topologyId(endpoint))]; ok {
+			return node.Name
+		}
+		return endpoint
+	}
+	var links []Link
+	attached := make(map[int][]Endpoint)
+	for _, link := range t.Links {
+		if link.SourceType != "node" {
+			continue
+		}
+		source := Endpoint{Node: name(link.Source), Interface: link.SourceLabel}
+		if link.DestinationType == "node" {
+			kind := InterfaceKind(link.Type)
+			if kind == "" {
+				kind = InterfaceSerial
+			}
+			links = append(links, Link{A: source, B: Endpoint{Node: name(link.Destination), Interface: link.DestinationLabel}, Kind: kind, NetworkId: link.NetworkId})
+			continue
+		}
+		attached[link.NetworkId] = append(attached[link.NetworkId], source)
+	}
+	for id, endpoints := range attached {
+		network := t.Networks[strconv.Itoa(id)]
+		hidden := network.Visibility == "0"
+		if hidden && len(endpoints) == 2 {
+			sort.Slice(endpoints, func(i, j int) bool { return endpoints[i].String() < endpoints[j].String() })
+			links = append(links, Link{A: endpoints[0], B: endpoints[1], Kind: InterfaceEthernet, NetworkId: id, Network: network.Name, Hidden: true})
+			continue
+		}
+		for _, endpoint := range endpoints {
+			links = append(links, Link{A: endpoint, Kind: InterfaceEthernet, NetworkId: id, Network: network.Name, Hidden: hidden})
+		}
+	}
+	for i := range links {
+		if links[i].B != (Endpoint{}) && links[i].B.String() < links[i].A.String() {
+			links[i].A, links[i].B = links[i].B, links[i].A
+		}
+	}
+	sort.Slice(links, func(i, j int) bool {
+		if links[i].A != links[j].A {
+			return links[i].A.String() < links[j].A.String()
+		}
+		return links[i].B.String() < links[j].B.String()
+	})
+	return links
+}
@@ -0,0 +1,224 @@
+package evengsdk
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+	"net/netip"
+	"sort"
+	"strconv"
+)
+
+// ManagementOptions configures AttachManagement and PlanManagement.
+type ManagementOptions struct {
+	// Type is the cloud the interfaces are attached to. Defaults to pnet0.
+	Type NetworkType
+	// Name is the name of the cloud network when it has to be created. Defaults to "Management".
+	Name string
+	// Left and Top place the cloud network when it has to be created.
+	Left int
+	Top  int
+	// Interface returns the name of the management interface of a node, nodes for which it returns "" are skipped.
+	// Defaults to the ethernet interface with the lowest index.
+	Interface func(node Node) string
+	// Prefix enables the static management addressing plan when it is valid.
+	Prefix netip.Prefix
+	// Gateway defaults to the first address of Prefix.
+	Gateway netip.Addr
+	// HostOffset is added to the node id to give the host part of the node address. Defaults to 10.
+	HostOffset int
+}
+
+// ManagementResult is the outcome of AttachManagement.
+type ManagementResult struct {
+	Network Network
+	// Attachments are the management interfaces attached to the cloud, including the ones that already were.
+	Attachments []Attachment
+	// Plan is nil unless ManagementOptions.Prefix is set.
+	Plan *ManagementPlan
+}
+
+// ManagementPlan is the static addressing of the management interfaces, for labs without DHCP on the management cloud.
+type ManagementPlan struct {
+	Prefix  netip.Prefix        `json:"prefix"`
+	Gateway netip.Addr          `json:"gateway"`
+	Hosts   []ManagementAddress `json:"hosts"`
+}
+
+type ManagementAddress struct {
+	NodeId    int          `json:"node_id"`
+	Node      string       `json:"node"`
+	Interface string       `json:"interface"`
+	Address   netip.Prefix `json:"address"`
+}
+
+// AttachManagement attaches the management interface of every node in the specified path to a shared cloud network.
+// The path should be the full path to the lab file, including the extension (e.g. /path/to/labfile.unl).
+// The first network of the cloud type is reused, a new one is created otherwise. Interfaces connected to another network are reported as errors,
+// the other nodes are still attached.
+func (s *NetworkService) AttachManagement(path string, opts ManagementOptions) (*ManagementResult, error) {
+	if opts.Type == "" {
+		opts.Type = NetworkPnet0
+	}
+	if !opts.Type.Cloud() {
+		return nil, errors.New("Network type " + string(opts.Type) + " is not a cloud")
+	}
+	if opts.Name == "" {
+		opts.Name = "Management"
+	}
+	nodes, err := s.client.Node.GetNodes(path)
+	if err != nil {
+		return nil, err
+	}
+	networks, err := s.GetNetworks(path)
+	if err != nil {
+		return nil, err
+	}
+	var network *Network
+	for _, n := range networks {
+		if n.Type == opts.Type && (network == nil || n.Id < network.Id) {
+			network = &n
+		}
+	}
+	if network == nil {
+		network = &Network{Name: opts.Name, Type: opts.Type, Left: opts.Left, Top: opts.Top, Visibility: "1"}
+		if err := s.CreateNetwork(path, network); err != nil {
+			return nil, err
+		}
+	}
+
+	sorted := make([]Node, 0, len(nodes))
+	for _, node := range nodes {
+		sorted = append(sorted, node)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Id < sorted[j].Id })
+	result := &ManagementResult{Network: *network}
+	var errs []error
+	for _, node := range sorted {
+		interfaces, err := s.client.Node.GetNodeInterfaces(path, node.Id)
+		if err != nil {
+			errs = append(errs, errors.New(node.Name+": "+err.Error()))
+			continue
+		}
+		var ref InterfaceRef
+		var intf Interface
+		if opts.Interface != nil {
+			name := opts.Interface(node)
+			if name == "" {
+				continue
+			}
+			var ok bool
+			if ref, intf, ok = interfaces.Find(name); !ok || ref.Kind != InterfaceEthernet {
+				errs = append(errs, errors.New(node.Name+": Ethernet interface "+name+" not found"))
+				continue
+			}
+		} else {
+			index := -1
+			for i := range interfaces.Ethernet {
+				if index < 0 || i < index {
+					index = i
+				}
+			}
+			if index < 0 {
+				continue
+			}
+			intf = interfaces.Ethernet[index]
+			ref = InterfaceRef{Kind: InterfaceEthernet, Index: index, Name: intf.Name}
+		}
+		switch intf.NetworkId {
+		case network.Id:
+		case 0:
+			if err := s.client.Node.UpdateNodeInterface(path, node.Id, ref.Index, network.Id); err != nil {
+				errs = append(errs, errors.New(node.Name+": "+err.Error()))
+				continue
+			}
+		default:
+			errs = append(errs, errors.New(node.Name+": Interface "+ref.Name+" is already connected to network "+strconv.Itoa(intf.NetworkId)))
+			continue
+		}
+		result.Attachments = append(result.Attachments, Attachment{NodeId: node.Id, Interface: ref.Name, Index: ref.Index, NetworkId: network.Id})
+	}
+	if opts.Prefix.IsValid() {
+		plan, err := PlanManagement(nodes, result.Attachments, opts)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		result.Plan = plan
+	}
+	return result, errors.Join(errs...)
+}
+
+// PlanManagement gives a static address to every management interface in attachments, from opts.Prefix.
+// The host part of an address is the node id plus opts.HostOffset, so addresses do not change when nodes are added or removed.
+func PlanManagement(nodes map[string]Node, attachments []Attachment, opts ManagementOptions) (*ManagementPlan, error) {
+	if !opts.Prefix.IsValid() {
+		return nil, errors.New("Invalid management prefix")
+	}
+	prefix := opts.Prefix.Masked()
+	offset := opts.HostOffset
+	if offset == 0 {
+		offset = 10
+	}
+	gateway := opts.Gateway
+	if !gateway.IsValid() {
+		gateway = addressNth(prefix, big.NewInt(1))
+	}
+	if !prefix.Contains(gateway) {
+		return nil, errors.New("Gateway " + gateway.String() + " is outside of " + prefix.String())
+	}
+	plan := &ManagementPlan{Prefix: prefix, Gateway: gateway}
+	last := addressLast(prefix)
+	for _, att := range attachments {
+		addr := addressNth(prefix, big.NewInt(int64(att.NodeId+offset)))
+		if !prefix.Contains(addr) || (prefix.Addr().Is4() && prefix.Bits() < 31 && addr == last) {
+			return nil, errors.New("Management prefix " + prefix.String() + " is too small for node " + strconv.Itoa(att.NodeId))
+		}
+		if addr == gateway {
+			return nil, errors.New("Address of node " + strconv.Itoa(att.NodeId) + " is the gateway " + gateway.String())
+		}
+		plan.Hosts = append(plan.Hosts, ManagementAddress{
+			NodeId:    att.NodeId,
+			Node:      nodes[strconv.Itoa(att.NodeId)].Name,
+			Interface: att.Interface,
+			Address:   netip.PrefixFrom(addr, prefix.Bits()),
+		})
+	}
+	sort.Slice(plan.Hosts, func(i, j int) bool { return plan.Hosts[i].NodeId < plan.Hosts[j].NodeId })
+	return plan, nil
+}
+
+// WriteJSON writes the plan as indented JSON.
+func (p *ManagementPlan) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(p)
+}
+
+// WriteCSV writes the plan as CSV with one row per node.
+func (p *ManagementPlan) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"node_id", "node", "interface", "address", "gateway"})
+	for _, host := range p.Hosts {
+		cw.Write([]string{strconv.Itoa(host.NodeId), host.Node, host.Interface, host.Address.String(), p.Gateway.String()})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// TemplateVars returns the plan as template variables keyed by node name, to be merged into ConfigRenderer.NodeVars.
+// Each node has "mgmt_interface", "mgmt_address" (address/length), "mgmt_ip", "mgmt_netmask" and "mgmt_gateway".
+func (p *ManagementPlan) TemplateVars() map[string]map[string]interface{} {
+	vars := make(map[string]map[string]interface{}, len(p.Hosts))
+	for _, host := range p.Hosts {
+		vars[host.Node] = map[string]interface{}{
+			"mgmt_interface": host.Interface,
+			"mgmt_address":   host.Address.String(),
+			"mgmt_ip":        host.Address.Addr().String(),
+			"mgmt_netmask":   configNetmask(host.Address),
+			"mgmt_gateway":   p.Gateway.String(),
+		}
+	}
+	return vars
+}
@@ -0,0 +1,342 @@
+package evengsdk
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MigrateOptions configures MigrateLab.
+type MigrateOptions struct {
+	// ExportConfigs saves the running config of the running source nodes as their startup config before copying the configs.
+	ExportConfigs bool
+	// Force migrates the lab even when templates or images are missing on the destination, the affected nodes are reported.
+	Force bool
+}
+
+// MigrationIssue is something MigrateLab could not carry over.
+type MigrationIssue struct {
+	// Kind is the kind of item: lab, template, image, export, node, icon, network, interface, config, textobject or picture.
+	Kind string
+	// Item names the item, such as a node name.
+	Item string
+	Err  error
+}
+
+func (i MigrationIssue) String() string {
+	return i.Kind + " " + i.Item + ": " + i.Err.Error()
+}
+
+// MigrationReport describes a lab migration.
+type MigrationReport struct {
+	Source      string
+	Destination string
+	// Nodes and Networks map the ids in the source lab to the ids in the destination lab.
+	Nodes       map[int]int
+	Networks    map[int]int
+	Configs     int
+	TextObjects int
+	Pictures    int
+	Issues      []MigrationIssue
+}
+
+// Err joins the issues of the migration, it is nil when everything was carried over.
+func (r *MigrationReport) Err() error {
+	errs := make([]error, len(r.Issues))
+	for i, issue := range r.Issues {
+		errs[i] = errors.New(issue.String())
+	}
+	return errors.Join(errs...)
+}
+
+func (r *MigrationReport) issue(kind string, item string, err error) {
+	r.Issues = append(r.Issues, MigrationIssue{Kind: kind, Item: item, Err: err})
+}
+
+// MigrateLab copies the lab with the specified path on the source server to the destination path on the destination server.
+// Both paths should be the full path to the lab file, including the extension (e.g. /path/to/labfile.unl).
+// The nodes, networks, links, startup configs, text objects and pictures are copied. The templates and images of the nodes
+// are checked on the destination first, and the migration is refused when some are missing unless Force is set.
+// Of the lab itself, only the author, description, version and body are copied, its other settings (e.g. the script timeout)
+// keep the defaults of the destination.
+// Items that cannot be copied are listed in the report, the error is MigrationReport.Err.
+// The destination lab must not exist. When a node or a network cannot be created, the destination lab is deleted again,
+// the other items that fail are only reported and leave an incomplete lab in place.
+func MigrateLab(src *Client, srcPath string, dst *Client, dstPath string, opts ...MigrateOptions) (*MigrationReport, error) {
+	var options MigrateOptions
+	if len(opts) > 0 {
+		options = opts[0]
+	}
+	report := &MigrationReport{Source: srcPath, Destination: dstPath, Nodes: make(map[int]int), Networks: make(map[int]int)}
+	fail := func(kind string, item string, err error) (*MigrationReport, error) {
+		report.issue(kind, item, err)
+		return report, report.Err()
+	}
+
+	lab, err := src.Lab.GetLab(srcPath)
+	if err != nil {
+		return fail("lab", srcPath, err)
+	}
+	if _, err := dst.Lab.GetLab(dstPath); err == nil {
+		return fail("lab", dstPath, errors.New("Destination lab already exists"))
+	}
+	nodes, err := src.Node.GetNodes(srcPath)
+	if err != nil {
+		return fail("lab", srcPath, err)
+	}
+	networks, err := src.Network.GetNetworks(srcPath)
+	if err != nil {
+		return fail("lab", srcPath, err)
+	}
+	sorted := make([]Node, 0, len(nodes))
+	for _, node := range nodes {
+		sorted = append(sorted, node)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Id < sorted[j].Id })
+
+	// Preflight: every template and image must be installed on the destination.
+	skip := make(map[int]bool)
+	var checkErr *ImageCheckError
+	if err := dst.Node.CheckImages(sorted); errors.As(err, &checkErr) {
+		for _, problem := range checkErr.Problems {
+			kind := "image"
+			if strings.HasPrefix(problem.Reason, "Unknown template") {
+				kind = "template"
+			}
+			report.issue(kind, problem.Name, errors.New(problem.Reason))
+			skip[problem.NodeId] = true
+		}
+		if !options.Force {
+			return report, report.Err()
+		}
+	} else if err != nil {
+		return fail("lab", dstPath, err)
+	}
+
+	if options.ExportConfigs {
+		for _, node := range sorted {
+			if node.Status.Running() {
+				if err := src.Node.ExportNodeConfig(srcPath, node.Id); err != nil {
+					report.issue("export", node.Name, err)
+				}
+			}
+		}
+	}
+
+	err = dst.Lab.CreateLab(dstPath, Lab{Author: lab.Author, Body: lab.Body, Description: lab.Description, Version: lab.Version, Name: lab.Name})
+	if err != nil {
+		return fail("lab", dstPath, err)
+	}
+
+	created := migrateNetworks(dst, dstPath, networks, report)
+	for _, node := range sorted {
+		if !created {
+			break
+		}
+		if !skip[node.Id] {
+			created = migrateNode(src, srcPath, dst, dstPath, node, report)
+		}
+	}
+	if !created {
+		if err := dst.Lab.DeleteLab(dstPath); err != nil {
+			report.issue("lab", dstPath, err)
+		}
+		return report, report.Err()
+	}
+	migrateInterfaces(src, srcPath, dst, dstPath, sorted, report)
+	// EVE-NG makes hidden networks visible again when interfaces are attached to them.
+	for _, network := range networks {
+		if network.Visibility != "0" || report.Networks[network.Id] == 0 {
+			continue
+		}
+		network.Id = report.Networks[network.Id]
+		if err := dst.Network.UpdateNetwork(dstPath, &network); err != nil {
+			report.issue("network", network.Name, err)
+		}
+	}
+	migrateObjects(src, srcPath, dst, dstPath, report)
+	return report, report.Err()
+}
+
+// migrateNetworks creates the networks on the destination, by increasing id.
+// It stops at the first network that cannot be created and returns false.
+func migrateNetworks(dst *Client, dstPath string, networks map[string]Network, report *MigrationReport) bool {
+	sorted := make([]Network, 0, len(networks))
+	for _, network := range networks {
+		sorted = append(sorted, network)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Id < sorted[j].Id })
+	for _, network := range sorted {
+		id := network.Id
+		network.Id = 0
+		network.Count = 0
+		if err := dst.Icon.ValidateNetwork(network.Icon); err != nil {
+			report.issue("icon", network.Name, err)
+			network.Icon = ""
+		}
+		if err := dst.Network.CreateNetwork(dstPath, &network); err != nil {
+			report.issue("network", network.Name, err)
+			return false
+		}
+		report.Networks[id] = network.Id
+	}
+	return true
+}
+
+// migrateNode creates the node on the destination and copies its startup config.
+// The node is fetched again, GetNodes does not return the settings specific to its type (e.g. qemu_options).
+// It returns false when the node cannot be fetched or created.
+func migrateNode(src *Client, srcPath string, dst *Client, dstPath string, listed Node, report *MigrationReport) bool {
+	id := listed.Id
+	fetched, err := src.Node.GetNode(srcPath, id)
+	if err != nil {
+		report.issue("node", listed.Name, err)
+		return false
+	}
+	node := *fetched
+	node.Id = 0
+	node.Status = NodeStatusStopped
+	node.Url = ""
+	node.Uuid = ""
+	if err := dst.Icon.Validate(node.Icon); err != nil {
+		report.issue("icon", node.Name, err)
+		node.Icon = ""
+	}
+	if err := dst.Node.CreateNode(dstPath, &node); err != nil {
+		report.issue("node", node.Name, err)
+		return false
+	}
+	report.Nodes[id] = node.Id
+	config, err := src.Node.GetNodeConfig(srcPath, id)
+	if err != nil {
+		report.issue("config", node.Name, err)
+		return true
+	}
+	if config == "" {
+		return true
+	}
+	if err := dst.Node.UpdateNodeConfig(dstPath, node.Id, config); err != nil {
+		report.issue("config", node.Name, err)
+		return true
+	}
+	report.Configs++
+	return true
+}
+
+// migrateInterfaces attaches the ethernet interfaces to the migrated networks and connects the serial interfaces.
+func migrateInterfaces(src *Client, srcPath string, dst *Client, dstPath string, nodes []Node, report *MigrationReport) {
+	for _, node := range nodes {
+		id, ok := report.Nodes[node.Id]
+		if !ok {
+			continue
+		}
+		interfaces, err := src.Node.GetNodeInterfaces(srcPath, node.Id)
+		if err != nil {
+			report.issue("interface", node.Name, err)
+			continue
+		}
+		indexes := make([]int, 0, len(interfaces.Ethernet))
+		for index := range interfaces.Ethernet {
+			indexes = append(indexes, index)
+		}
+		sort.Ints(indexes)
+		for _, index := range indexes {
+			intf := interfaces.Ethernet[index]
+			if intf.NetworkId == 0 {
+				continue
+			}
+			network, ok := report.Networks[intf.NetworkId]
+			if !ok {
+				report.issue("interface", node.Name+" "+intf.Name, errors.New("Network "+strconv.Itoa(intf.NetworkId)+" was not migrated"))
+				continue
+			}
+			if err := dst.Node.UpdateNodeInterface(dstPath, id, index, network); err != nil {
+				report.issue("interface", node.Name+" "+intf.Name, err)
+			}
+		}
+		indexes = indexes[:0]
+		for index := range interfaces.Serial {
+			indexes = append(indexes, index)
+		}
+		sort.Ints(indexes)
+		for _, index := range indexes {
+			intf := interfaces.Serial[index]
+			// Each serial link is connected once, from its end with the lowest node id and interface index.
+			if intf.RemoteId == 0 || intf.RemoteId < node.Id || intf.RemoteId == node.Id && intf.RemoteIf < index {
+				continue
+			}
+			remote, ok := report.Nodes[intf.RemoteId]
+			if !ok {
+				report.issue("interface", node.Name+" "+intf.Name, errors.New("Node "+strconv.Itoa(intf.RemoteId)+" was not migrated"))
+				continue
+			}
+			if err := dst.Node.ConnectSerial(dstPath, id, index, remote, intf.RemoteIf); err != nil {
+				report.issue("interface", node.Name+" "+intf.Name, err)
+			}
+		}
+	}
+}
+
+// pictureNode matches the node references of picture image maps (e.g. {{NODE3}}).
+var pictureNode = regexp.MustCompile(`\{\{NODE(\d+)\}\}`)
+
+// migrateObjects copies the text objects and pictures of the lab.
+func migrateObjects(src *Client, srcPath string, dst *Client, dstPath string, report *MigrationReport) {
+	objects, err := src.Lab.GetTextObjects(srcPath)
+	if err != nil {
+		report.issue("textobject", srcPath, err)
+	}
+	ids := make([]int, 0, len(objects))
+	byId := make(map[int]TextObject, len(objects))
+	for _, object := range objects {
+		ids = append(ids, object.Id)
+		byId[object.Id] = object
+	}
+	sort.Ints(ids)
+	for _, id := range ids {
+		object := byId[id]
+		if err := dst.Lab.CreateTextObject(dstPath, &object); err != nil {
+			report.issue("textobject", object.Name, err)
+			continue
+		}
+		report.TextObjects++
+	}
+
+	pictures, err := src.Lab.GetPictures(srcPath)
+	if err != nil {
+		report.issue("picture", srcPath, err)
+	}
+	ids = ids[:0]
+	for _, picture := range pictures {
+		ids = append(ids, picture.Id)
+	}
+	sort.Ints(ids)
+	for _, id := range ids {
+		picture, err := src.Lab.GetPicture(srcPath, id)
+		if err != nil {
+			report.issue("picture", pictures[strconv.Itoa(id)].Name, err)
+			continue
+		}
+		image, err := src.Lab.GetPictureData(srcPath, id)
+		if err != nil {
+			report.issue("picture", picture.Name, err)
+			continue
+		}
+		// Image maps link their areas to nodes by id, which change with the migration.
+		picture.Map = pictureNode.ReplaceAllStringFunc(picture.Map, func(match string) string {
+			id, _ := strconv.Atoi(pictureNode.FindStringSubmatch(match)[1])
+			if migrated, ok := report.Nodes[id]; ok {
+				return "{{NODE" + strconv.Itoa(migrated) + "}}"
+			}
+			report.issue("picture", picture.Name, errors.New("Image map links to node "+strconv.Itoa(id)+" which was not migrated"))
+			return match
+		})
+		if err := dst.Lab.CreatePicture(dstPath, picture, image); err != nil {
+			report.issue("picture", picture.Name, err)
+			continue
+		}
+		report.Pictures++
+	}
+}
@@ -3,13 +3,19 @@ package evengsdk
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/url"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 )
 
 type NetworkService struct {
 	client *Client
+	lock   sync.Mutex
+	// types caches the result of GetNetworkTypes.
+	types []NetworkTypeInfo
 }
 
 type Network struct {
@@ -18,11 +24,43 @@ type Network struct {
 	Left       int         `json:"left"`
 	Name       string      `json:"name"`
 	Top        int         `json:"top"`
-	Type       string      `json:"type"`
+	Type       NetworkType `json:"type"`
 	Visibility json.Number `json:"visibility"`
 	Icon       string      `json:"icon"`
 }
 
+// NetworkType is the kind of a network: a bridge, an Open vSwitch or a cloud bridged to a host interface (pnet0 to pnet9).
+type NetworkType string
+
+const (
+	NetworkBridge NetworkType = "bridge"
+	NetworkOvs    NetworkType = "ovs"
+	NetworkPnet0  NetworkType = "pnet0"
+	NetworkPnet1  NetworkType = "pnet1"
+	NetworkPnet2  NetworkType = "pnet2"
+	NetworkPnet3  NetworkType = "pnet3"
+	NetworkPnet4  NetworkType = "pnet4"
+	NetworkPnet5  NetworkType = "pnet5"
+	NetworkPnet6  NetworkType = "pnet6"
+	NetworkPnet7  NetworkType = "pnet7"
+	NetworkPnet8  NetworkType = "pnet8"
+	NetworkPnet9  NetworkType = "pnet9"
+)
+
+// NetworkPnet returns the cloud network type bridged to the host interface pnetN.
+func NetworkPnet(n int) NetworkType {
+	return NetworkType("pnet" + strconv.Itoa(n))
+}
+
+func (t NetworkType) String() string {
+	return string(t)
+}
+
+// Cloud reports whether the network is bridged to a host interface.
+func (t NetworkType) Cloud() bool {
+	return strings.HasPrefix(string(t), "pnet")
+}
+
 // GetNetworks returns all networks in the specified path.
 // The path should be the full path to the lab file, including the extension (e.g. /path/to/labfile.unl).
 func (s *NetworkService) GetNetworks(path string) (map[string]Network, error) {
@@ -68,7 +106,14 @@ func (s *NetworkService) GetNetwork(path string, id int) (Network, error) {
 // CreateNetwork creates a new network in the specified path.
 // The path should be the full path to the lab file, including the extension (e.g. /path/to/labfile.unl).
 // The network parameter should be a pointer to a Network struct. The Id field will be set to the id of the new network.
+// The network type is checked with ValidateType, and its icon with IconService.ValidateNetwork, before the request is sent.
 func (s *NetworkService) CreateNetwork(path string, network *Network) error {
+	if err := s.ValidateType(network.Type); err != nil {
+		return err
+	}
+	if err := s.client.Icon.ValidateNetwork(network.Icon); err != nil {
+		return err
+	}
 	name := path[strings.LastIndex(path, "/")+1:]
 	path = path[:strings.LastIndex(path, "/")+1]
 	data, err := json.Marshal(network)
@@ -105,17 +150,78 @@ func (s *NetworkService) DeleteNetwork(path string, id int) error {
 	return err
 }
 
-// GetNetworksList returns a list of all networks.
-// This is a convenience method that returns the names of all networks available in the system.
-func (s *NetworkService) GetNetworksList() ([]string, error) {
+// NetworkTypeInfo is a network type available on the server, with its display name (e.g. pnet0, Management(Cloud0)).
+type NetworkTypeInfo struct {
+	Type NetworkType
+	Name string
+}
+
+// GetNetworkTypes returns the network types available on the server, sorted by type.
+func (s *NetworkService) GetNetworkTypes() ([]NetworkTypeInfo, error) {
 	eve, _, err := s.client.Do(context.Background(), "GET", "api/list/networks", nil)
 	if err != nil {
 		return nil, err
 	}
-	data := eve.Data.(map[string]interface{})
-	keys := make([]string, 0, len(data))
-	for key := range data {
-		keys = append(keys, key)
+	data, ok := eve.Data.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("Unexpected network list")
+	}
+	types := make([]NetworkTypeInfo, 0, len(data))
+	for key, value := range data {
+		name := topologyString(value)
+		if name == "" {
+			name = key
+		}
+		types = append(types, NetworkTypeInfo{Type: NetworkType(key), Name: name})
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i].Type < types[j].Type })
+	s.lock.Lock()
+	s.types = types
+	s.lock.Unlock()
+	return types, nil
+}
+
+// GetNetworksList returns the names of all network types available on the server, sorted.
+func (s *NetworkService) GetNetworksList() ([]string, error) {
+	types, err := s.GetNetworkTypes()
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, len(types))
+	for i, t := range types {
+		keys[i] = string(t.Type)
 	}
 	return keys, nil
 }
+
+// InvalidateCache discards the network types cached by GetNetworkTypes, the next validation fetches them again.
+func (s *NetworkService) InvalidateCache() {
+	s.lock.Lock()
+	s.types = nil
+	s.lock.Unlock()
+}
+
+// ValidateType returns an error when the network type is not available on the server.
+// The list of network types is fetched once and then reused, until InvalidateCache is called.
+func (s *NetworkService) ValidateType(t NetworkType) error {
+	s.lock.Lock()
+	types := s.types
+	s.lock.Unlock()
+	if types == nil {
+		var err error
+		if types, err = s.GetNetworkTypes(); err != nil {
+			return err
+		}
+	}
+	names := make([]string, len(types))
+	for i, info := range types {
+		if info.Type == t {
+			return nil
+		}
+		names[i] = string(info.Type)
+	}
+	if t == "" {
+		return errors.New("Network type is required")
+	}
+	return errors.New("Invalid network type " + strconv.Quote(string(t)) + ", expected one of " + strings.Join(names, ", "))
+}
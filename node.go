@@ -4,7 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"net"
 	"net/url"
+	"reflect"
 	"strconv"
 	"strings"
 )
@@ -14,7 +16,7 @@ type NodeService struct {
 }
 
 type Node struct {
-	Console  string      `json:"console"`
+	Console  ConsoleType `json:"console"`
 	Delay    int         `json:"delay"`
 	Id       int         `json:"id"`
 	Left     int         `json:"left"`
@@ -22,20 +24,237 @@ type Node struct {
 	Image    string      `json:"image"`
 	Name     string      `json:"name"`
 	Ram      int         `json:"ram"`
-	Status   int         `json:"status"`
+	Status   NodeStatus  `json:"status"`
 	Template string      `json:"template"`
-	Type     string      `json:"type"`
+	Type     NodeType    `json:"type"`
 	Top      int         `json:"top"`
 	Url      string      `json:"url"`
 	Config   json.Number `json:"config"`
 	Cpu      int         `json:"cpu"`
 	Ethernet int         `json:"ethernet"`
 	Uuid     string      `json:"uuid"`
+	// Nvram is the NVRAM size in KB of iol and dynamips nodes.
+	Nvram int `json:"nvram,omitempty"`
+	QemuSettings
+	IolSettings
+	DynamipsSettings
+	DockerSettings
+	ProSettings
+	// Extra holds the fields returned by EVE-NG that have no field above, they are sent back as is by UpdateNode.
+	Extra map[string]json.RawMessage `json:"-"`
+	// decoded holds the JSON names of the fields the node was decoded with, UpdateNode sends them even when they are empty.
+	decoded map[string]bool
+}
+
+// QemuSettings are the node fields specific to qemu nodes.
+type QemuSettings struct {
+	FirstMac    string `json:"firstmac,omitempty"`
+	QemuOptions string `json:"qemu_options,omitempty"`
+	QemuVersion string `json:"qemu_version,omitempty"`
+	QemuArch    string `json:"qemu_arch,omitempty"`
+	QemuNic     string `json:"qemu_nic,omitempty"`
+	// CpuLimit enables the CPU limit of the node when it is 1.
+	CpuLimit int `json:"cpulimit,omitempty"`
+}
+
+// IolSettings are the node fields specific to iol nodes.
+type IolSettings struct {
+	// Serial is the number of serial interface groups, each group holding four interfaces.
+	Serial int `json:"serial,omitempty"`
+	// Keepalive is 1 when the node sends keepalives on its serial interfaces.
+	Keepalive int `json:"keepalive,omitempty"`
+}
+
+// DynamipsSettings are the node fields specific to dynamips nodes.
+type DynamipsSettings struct {
+	IdlePc string `json:"idlepc,omitempty"`
+}
+
+// DockerSettings are the node fields specific to docker nodes.
+type DockerSettings struct {
+	// Environment holds the container environment, one NAME=value per line.
+	Environment string `json:"environment,omitempty"`
+	// ImagePull is the pull policy of the container image.
+	ImagePull string `json:"image_pull,omitempty"`
+}
+
+// ProSettings are the node fields only available in EVE-NG Pro.
+type ProSettings struct {
+	// Sat is the start after timer in seconds.
+	Sat      int    `json:"sat,omitempty"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	// Map is the configuration type of the node.
+	Map string `json:"map,omitempty"`
+}
+
+// nodeFields maps the JSON names of the fields of Node to their index.
+var nodeFields = jsonFields(reflect.TypeOf(Node{}), nil)
+
+func jsonFields(t reflect.Type, index []int) map[string][]int {
+	fields := make(map[string][]int)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fieldIndex := append(append([]int(nil), index...), i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			for name, index := range jsonFields(field.Type, fieldIndex) {
+				fields[name] = index
+			}
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields[name] = fieldIndex
+		}
+	}
+	return fields
+}
+
+// nodeJSON has the fields of Node without its JSON methods.
+type nodeJSON Node
+
+// UnmarshalJSON decodes a node, keeping the unknown fields in Extra.
+func (n *Node) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	var node nodeJSON
+	if err := json.Unmarshal(data, &node); err != nil {
+		return err
+	}
+	for key, value := range raw {
+		if _, ok := nodeFields[key]; ok {
+			if node.decoded == nil {
+				node.decoded = make(map[string]bool)
+			}
+			node.decoded[key] = true
+			continue
+		}
+		if node.Extra == nil {
+			node.Extra = make(map[string]json.RawMessage)
+		}
+		node.Extra[key] = value
+	}
+	*n = Node(node)
+	return nil
+}
+
+// MarshalJSON encodes a node along with its Extra fields.
+func (n Node) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(nodeJSON(n))
+	if err != nil || len(n.Extra) == 0 {
+		return data, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for key, value := range n.Extra {
+		if _, ok := nodeFields[key]; !ok {
+			fields[key] = value
+		}
+	}
+	return json.Marshal(fields)
+}
+
+// updateBody encodes the node for UpdateNode.
+// Empty settings are omitted, as for new nodes, unless the node was decoded with them: the server then knows them,
+// and sending them lets a node fetched with GetNode reset a setting to its zero value.
+func (n *Node) updateBody() ([]byte, error) {
+	data, err := json.Marshal(n)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	v := reflect.ValueOf(*n)
+	for name := range n.decoded {
+		if _, ok := fields[name]; ok {
+			continue
+		}
+		value, err := json.Marshal(v.FieldByIndex(nodeFields[name]).Interface())
+		if err != nil {
+			return nil, err
+		}
+		fields[name] = value
+	}
+	return json.Marshal(fields)
+}
+
+// ConsoleAddress returns the host and port of the node console parsed from the Url field (e.g. telnet://10.0.0.1:32769).
+func (n *Node) ConsoleAddress() (string, int, error) {
+	u, err := url.Parse(n.Url)
+	if err != nil {
+		return "", 0, err
+	}
+	host, port, err := net.SplitHostPort(u.Host)
+	if err != nil {
+		return "", 0, errors.New("Console URL has no host and port")
+	}
+	portNum, err := strconv.Atoi(port)
+	if err != nil {
+		return "", 0, err
+	}
+	return host, portNum, nil
 }
 
 type Interface struct {
 	Name      string `json:"name"`
 	NetworkId int    `json:"network_id"`
+	// RemoteId and RemoteIf are the node and interface index at the other end of a serial link.
+	RemoteId int `json:"remote_id,omitempty"`
+	RemoteIf int `json:"remote_if,omitempty"`
+}
+
+// InterfaceKind is the kind of a node interface.
+type InterfaceKind string
+
+const (
+	InterfaceEthernet InterfaceKind = "ethernet"
+	InterfaceSerial   InterfaceKind = "serial"
+)
+
+// InterfaceRef identifies an interface of a node.
+// Index is the interface id used by EVE-NG, ethernet and serial interfaces of a node never share an index.
+type InterfaceRef struct {
+	Kind  InterfaceKind
+	Index int
+	Name  string
+}
+
+// UnmarshalJSON accepts the remote node and interface of serial links as numbers, strings or empty strings.
+func (i *Interface) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Name      string      `json:"name"`
+		NetworkId int         `json:"network_id"`
+		RemoteId  interface{} `json:"remote_id"`
+		RemoteIf  interface{} `json:"remote_if"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*i = Interface{Name: raw.Name, NetworkId: raw.NetworkId}
+	i.RemoteId, _ = strconv.Atoi(templateValue(raw.RemoteId))
+	i.RemoteIf, _ = strconv.Atoi(templateValue(raw.RemoteIf))
+	return nil
+}
+
+// Find returns the interface with the specified name, either ethernet or serial.
+func (i *Interfaces) Find(name string) (InterfaceRef, Interface, bool) {
+	for index, intf := range i.Ethernet {
+		if intf.Name == name {
+			return InterfaceRef{Kind: InterfaceEthernet, Index: index, Name: name}, intf, true
+		}
+	}
+	for index, intf := range i.Serial {
+		if intf.Name == name {
+			return InterfaceRef{Kind: InterfaceSerial, Index: index, Name: name}, intf, true
+		}
+	}
+	return InterfaceRef{}, Interface{}, false
 }
 
 // InterfaceEntry can handle both slice and map structures.
@@ -112,7 +331,14 @@ func (s *NodeService) GetNode(path string, node int) (*Node, error) {
 // CreateNode creates a new node in the specified path.
 // The path should be the full path to the lab file, including the extension (e.g. /path/to/labfile.unl).
 // The node should be a pointer to a Node struct. The Id field will be set to the id of the new node.
+// The node is checked with Validate, and its icon with IconService.Validate, before the request is sent.
 func (s *NodeService) CreateNode(path string, node *Node) error {
+	if err := node.Validate(); err != nil {
+		return err
+	}
+	if err := s.client.Icon.Validate(node.Icon); err != nil {
+		return err
+	}
 	name := path[strings.LastIndex(path, "/")+1:]
 	path = path[:strings.LastIndex(path, "/")+1]
 	body, err := json.Marshal(node)
@@ -129,10 +355,12 @@ func (s *NodeService) CreateNode(path string, node *Node) error {
 
 // UpdateNode updates the node with the specified id in the specified path.
 // The path should be the full path to the lab file, including the extension (e.g. /path/to/labfile.unl).
+// Empty settings are only sent when the node was fetched with them, such as by GetNode, so that they can be reset to their zero value.
+// Nodes built by hand or returned by GetNodes leave the settings they do not set untouched.
 func (s *NodeService) UpdateNode(path string, node *Node) error {
 	name := path[strings.LastIndex(path, "/")+1:]
 	path = path[:strings.LastIndex(path, "/")+1]
-	body, err := json.Marshal(node)
+	body, err := node.updateBody()
 	if err != nil {
 		return err
 	}
@@ -143,6 +371,19 @@ func (s *NodeService) UpdateNode(path string, node *Node) error {
 	return nil
 }
 
+// updateNodeFields updates only the specified fields, keyed by JSON name, of the node with the specified id in the specified path.
+// The other fields, including the settings GetNodes does not return, are left untouched.
+func (s *NodeService) updateNodeFields(path string, nodeId int, fields map[string]interface{}) error {
+	name := path[strings.LastIndex(path, "/")+1:]
+	path = path[:strings.LastIndex(path, "/")+1]
+	body, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	_, _, err = s.client.Do(context.Background(), "PUT", "api/labs/"+path+url.QueryEscape(name)+"/nodes/"+strconv.Itoa(nodeId), body)
+	return err
+}
+
 // DeleteNode deletes the node with the specified id in the specified path.
 // The path should be the full path to the lab file, including the extension (e.g. /path/to/labfile.unl).
 func (s *NodeService) DeleteNode(path string, nodeId int) error {
@@ -169,21 +410,23 @@ func (s *NodeService) startNodesCommunity(path string) error {
 }
 
 func (s *NodeService) startNodesPro(path string) error {
-	nodes, err := s.GetNodes(path)
+	plan, err := s.planLab(path, StartPlanOptions{})
 	if err != nil {
 		return err
 	}
-	for _, node := range nodes {
-		err = s.StartNode(path, node.Id)
-		if err != nil {
-			return err
+	var ids []int
+	for _, wave := range plan {
+		for _, node := range wave.Nodes {
+			ids = append(ids, node.Id)
 		}
 	}
-	return nil
+	_, err = s.BulkStart(context.Background(), path, ids, BulkOptions{Concurrency: 1})
+	return err
 }
 
 // StartNodes starts all nodes in the specified path.
 // The path should be the full path to the lab file, including the extension (e.g. /path/to/labfile.unl).
+// To check the resources of the host first, use StartNodesWithOptions.
 func (s *NodeService) StartNodes(path string) error {
 	if s.client.isPro {
 		return s.startNodesPro(path)
@@ -233,7 +476,7 @@ func (s *NodeService) UpdateNodeInterface(path string, node int, intf int, netwo
 
 // UpdateNodeInterfaceStyle updates the style of the interface with the specified id of the node with the specified id in the specified path.
 // The path should be the full path to the lab file, including the extension (e.g. /path/to/labfile.unl).
-// The style parameter should be a Style struct. The attributes Node and Type will be set automatically.
+// The style parameter should be a Style struct. The attribute Node will be set automatically, Type defaults to ethernet.
 func (s *NodeService) UpdateNodeInterfaceStyle(path string, node int, style Style) error {
 	if !s.client.isPro {
 		return errors.New("This function is only available in the Pro version")
@@ -241,7 +484,9 @@ func (s *NodeService) UpdateNodeInterfaceStyle(path string, node int, style Styl
 	name := path[strings.LastIndex(path, "/")+1:]
 	path = path[:strings.LastIndex(path, "/")+1]
 	style.Node = strconv.Itoa(node)
-	style.Type = "ethernet"
+	if style.Type == "" {
+		style.Type = string(InterfaceEthernet)
+	}
 	data, err := json.Marshal(style)
 	if err != nil {
 		return err
@@ -255,44 +500,93 @@ func (s *NodeService) UpdateNodeInterfaceStyle(path string, node int, style Styl
 
 // UpdateNodeInterfaceStyleByName updates the style of the interface with the specified name of the node with the specified id in the specified path.
 // The path should be the full path to the lab file, including the extension (e.g. /path/to/labfile.unl).
-// The name should be the name of the interface (e.g. Gi0/0).
-// The style parameter should be a Style struct. The attributes Node, Type, InterfaceId and Id will be set automatically.
+// The name should be the name of the interface (e.g. Gi0/0 or s1/0).
+// The style parameter should be a Style struct. The attributes Node, Type and InterfaceId will be set automatically, as will Id for ethernet interfaces.
 func (s *NodeService) UpdateNodeInterfaceStyleByName(path string, node int, intf string, style Style) error {
-	index, inter, err := s.GetNodeInterface(path, node, intf)
+	ref, inter, err := s.GetNodeInterfaceRef(path, node, intf)
 	if err != nil {
 		return err
 	}
-	style.InterfaceId = strconv.Itoa(index)
-	style.Id = "network_id:" + strconv.Itoa(inter.NetworkId)
+	style.InterfaceId = strconv.Itoa(ref.Index)
+	style.Type = string(ref.Kind)
+	if ref.Kind == InterfaceEthernet {
+		style.Id = "network_id:" + strconv.Itoa(inter.NetworkId)
+	}
 	return s.UpdateNodeInterfaceStyle(path, node, style)
 }
 
 // GetNodeInterface returns the interface with the specified name of the node with the specified id in the specified path.
 // The path should be the full path to the lab file, including the extension (e.g. /path/to/labfile.unl).
-// The name should be the name of the interface (e.g. Gi0/0).
+// The name should be the name of the interface (e.g. Gi0/0 or s1/0), both ethernet and serial interfaces are searched.
 // returns the index of the interface, the interface and an error.
 func (s *NodeService) GetNodeInterface(path string, node int, intf string) (int, Interface, error) {
+	ref, inter, err := s.GetNodeInterfaceRef(path, node, intf)
+	return ref.Index, inter, err
+}
+
+// GetNodeInterfaceRef returns the reference and the state of the interface with the specified name of the node with the specified id in the specified path.
+// The path should be the full path to the lab file, including the extension (e.g. /path/to/labfile.unl).
+// The name should be the name of the interface (e.g. Gi0/0 or s1/0).
+func (s *NodeService) GetNodeInterfaceRef(path string, node int, intf string) (InterfaceRef, Interface, error) {
 	interfaces, err := s.GetNodeInterfaces(path, node)
 	if err != nil {
-		return 0, Interface{}, err
+		return InterfaceRef{}, Interface{}, err
 	}
-	for index, eth := range interfaces.Ethernet {
-		if eth.Name == intf {
-			return index, eth, nil
-		}
+	ref, inter, ok := interfaces.Find(intf)
+	if !ok {
+		return InterfaceRef{}, Interface{}, errors.New("Interface not found")
 	}
-	return 0, Interface{}, errors.New("Interface not found")
+	return ref, inter, nil
 }
 
 // UpdateNodeInterfaceName updates the interface with the specified name of the node with the specified id in the specified path.
 // The path should be the full path to the lab file, including the extension (e.g. /path/to/labfile.unl).
 // The name should be the name of the interface (e.g. Gi0/0).
+// Serial interfaces can only be disconnected this way, with a network of 0. Use ConnectSerial to connect them.
 func (s *NodeService) UpdateNodeInterfaceName(path string, node int, intf string, network int) error {
-	index, _, err := s.GetNodeInterface(path, node, intf)
+	ref, _, err := s.GetNodeInterfaceRef(path, node, intf)
+	if err != nil {
+		return err
+	}
+	if ref.Kind == InterfaceSerial && network != 0 {
+		return errors.New("Serial interfaces connect to another node, not to a network")
+	}
+	return s.UpdateNodeInterface(path, node, ref.Index, network)
+}
+
+// ConnectSerial connects the serial interface with the specified index of a node to a serial interface of another node, in the specified path.
+// The path should be the full path to the lab file, including the extension (e.g. /path/to/labfile.unl).
+// EVE-NG updates both ends of the link. Use UpdateNodeInterface with a network of 0 to disconnect it.
+func (s *NodeService) ConnectSerial(path string, node int, intf int, remoteNode int, remoteIntf int) error {
+	name := path[strings.LastIndex(path, "/")+1:]
+	path = path[:strings.LastIndex(path, "/")+1]
+	data, err := json.Marshal(map[string]interface{}{strconv.Itoa(intf): strconv.Itoa(remoteNode) + ":" + strconv.Itoa(remoteIntf)})
+	if err != nil {
+		return err
+	}
+	_, _, err = s.client.Do(context.Background(), "PUT", "api/labs/"+path+url.QueryEscape(name)+"/nodes/"+strconv.Itoa(node)+"/interfaces", data)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// ConnectSerialByName connects the serial interfaces with the specified names of two nodes in the specified path.
+// The path should be the full path to the lab file, including the extension (e.g. /path/to/labfile.unl).
+// The names should be the names of the interfaces (e.g. s1/0).
+func (s *NodeService) ConnectSerialByName(path string, node int, intf string, remoteNode int, remoteIntf string) error {
+	local, _, err := s.GetNodeInterfaceRef(path, node, intf)
 	if err != nil {
 		return err
 	}
-	return s.UpdateNodeInterface(path, node, index, network)
+	remote, _, err := s.GetNodeInterfaceRef(path, remoteNode, remoteIntf)
+	if err != nil {
+		return err
+	}
+	if local.Kind != InterfaceSerial || remote.Kind != InterfaceSerial {
+		return errors.New("Both interfaces should be serial interfaces")
+	}
+	return s.ConnectSerial(path, node, local.Index, remoteNode, remote.Index)
 }
 
 // StartNode starts the node with the specified id in the specified path.
@@ -340,6 +634,36 @@ func (s *NodeService) StopNode(path string, node int) error {
 	return nil
 }
 
+// WipeNode wipes the node with the specified id in the specified path, resetting it to its template image and startup config.
+// The path should be the full path to the lab file, including the extension (e.g. /path/to/labfile.unl).
+func (s *NodeService) WipeNode(path string, node int) error {
+	name := path[strings.LastIndex(path, "/")+1:]
+	path = path[:strings.LastIndex(path, "/")+1]
+	evengresp, _, err := s.client.Do(context.Background(), "GET", "api/labs/"+path+url.QueryEscape(name)+"/nodes/"+strconv.Itoa(node)+"/wipe", nil)
+	if err != nil {
+		return err
+	}
+	if evengresp.Status != "success" {
+		return errors.New(evengresp.Message)
+	}
+	return nil
+}
+
+// ExportNodeConfig saves the running config of the node with the specified id in the specified path as its startup config.
+// The path should be the full path to the lab file, including the extension (e.g. /path/to/labfile.unl).
+func (s *NodeService) ExportNodeConfig(path string, node int) error {
+	name := path[strings.LastIndex(path, "/")+1:]
+	path = path[:strings.LastIndex(path, "/")+1]
+	evengresp, _, err := s.client.Do(context.Background(), "PUT", "api/labs/"+path+url.QueryEscape(name)+"/nodes/"+strconv.Itoa(node)+"/export", nil)
+	if err != nil {
+		return err
+	}
+	if evengresp.Status != "success" {
+		return errors.New(evengresp.Message)
+	}
+	return nil
+}
+
 // GetNodeConfig returns the config of the node with the specified id in the specified path.
 // The path should be the full path to the lab file, including the extension (e.g. /path/to/labfile.unl).
 func (s *NodeService) GetNodeConfig(path string, node int) (string, error) {
@@ -399,12 +723,23 @@ func (s *NodeService) GetTemplates() (map[string]string, error) {
 	return templates, nil
 }
 
-func (s *NodeService) GetTemplate(name string) (map[string]interface{}, error) {
+// GetTemplate returns the template with the specified name (e.g. vios), with the default and allowed values of its options.
+func (s *NodeService) GetTemplate(name string) (*Template, error) {
 	eve, _, err := s.client.Do(context.Background(), "GET", "api/list/templates/"+name, nil)
 	if err != nil {
 		return nil, err
 	}
-	return eve.Data.(map[string]interface{}), nil
+	data, err := json.Marshal(eve.Data)
+	if err != nil {
+		return nil, err
+	}
+	var template Template
+	err = json.Unmarshal(data, &template)
+	if err != nil {
+		return nil, err
+	}
+	template.Name = name
+	return &template, nil
 }
 
 // some nodes have ethernet as map[string]interface{} instead of []interface{}, namely the IOL nodes
@@ -0,0 +1,155 @@
+package evengsdk
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+)
+
+// NodeStatus is the run state of a node as reported by EVE-NG.
+// It is sent and received as a number.
+type NodeStatus int
+
+const (
+	NodeStatusStopped NodeStatus = iota
+	// NodeStatusStarting is reported while the node is being built, before it runs.
+	NodeStatusStarting
+	NodeStatusRunning
+	// NodeStatusBusy is reported while the node runs and an operation, such as a config export, holds its lock.
+	NodeStatusBusy
+)
+
+var nodeStatusNames = map[NodeStatus]string{
+	NodeStatusStopped:  "stopped",
+	NodeStatusStarting: "starting",
+	NodeStatusRunning:  "running",
+	NodeStatusBusy:     "busy",
+}
+
+func (s NodeStatus) String() string {
+	if name, ok := nodeStatusNames[s]; ok {
+		return name
+	}
+	return "NodeStatus(" + strconv.Itoa(int(s)) + ")"
+}
+
+// Valid reports whether the status is one of the known statuses.
+func (s NodeStatus) Valid() bool {
+	_, ok := nodeStatusNames[s]
+	return ok
+}
+
+// Running reports whether the node runs, busy or not.
+func (s NodeStatus) Running() bool {
+	return s == NodeStatusRunning || s == NodeStatusBusy
+}
+
+func (s NodeStatus) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Itoa(int(s))), nil
+}
+
+// UnmarshalJSON accepts a number, a quoted number or a status name.
+func (s *NodeStatus) UnmarshalJSON(data []byte) error {
+	var number int
+	if err := json.Unmarshal(data, &number); err == nil {
+		*s = NodeStatus(number)
+		return nil
+	}
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return err
+	}
+	if number, err := strconv.Atoi(text); err == nil {
+		*s = NodeStatus(number)
+		return nil
+	}
+	for status, name := range nodeStatusNames {
+		if name == text {
+			*s = status
+			return nil
+		}
+	}
+	return errors.New("Invalid node status " + strconv.Quote(text))
+}
+
+// NodeType is the engine running a node.
+type NodeType string
+
+const (
+	NodeTypeQemu     NodeType = "qemu"
+	NodeTypeIol      NodeType = "iol"
+	NodeTypeDynamips NodeType = "dynamips"
+	NodeTypeDocker   NodeType = "docker"
+	NodeTypeVpcs     NodeType = "vpcs"
+)
+
+func (t NodeType) String() string {
+	return string(t)
+}
+
+// Valid reports whether the type is one of the known node types.
+func (t NodeType) Valid() bool {
+	_, ok := nodeConsoles[t]
+	return ok
+}
+
+// Consoles returns the console types supported by the node type, the first one being the default.
+func (t NodeType) Consoles() []ConsoleType {
+	return append([]ConsoleType(nil), nodeConsoles[t]...)
+}
+
+// ConsoleType is the protocol used to reach a node console.
+type ConsoleType string
+
+const (
+	ConsoleTelnet ConsoleType = "telnet"
+	ConsoleVnc    ConsoleType = "vnc"
+	ConsoleRdp    ConsoleType = "rdp"
+	ConsoleRdpTls ConsoleType = "rdp-tls"
+	ConsoleHttp   ConsoleType = "http"
+	ConsoleHttps  ConsoleType = "https"
+)
+
+func (c ConsoleType) String() string {
+	return string(c)
+}
+
+// Valid reports whether the console is one of the known console types.
+func (c ConsoleType) Valid() bool {
+	switch c {
+	case ConsoleTelnet, ConsoleVnc, ConsoleRdp, ConsoleRdpTls, ConsoleHttp, ConsoleHttps:
+		return true
+	}
+	return false
+}
+
+var nodeConsoles = map[NodeType][]ConsoleType{
+	NodeTypeQemu:     {ConsoleTelnet, ConsoleVnc, ConsoleRdp, ConsoleRdpTls},
+	NodeTypeIol:      {ConsoleTelnet},
+	NodeTypeDynamips: {ConsoleTelnet},
+	NodeTypeDocker:   {ConsoleTelnet, ConsoleVnc, ConsoleRdp, ConsoleHttp, ConsoleHttps},
+	NodeTypeVpcs:     {ConsoleTelnet},
+}
+
+// Validate checks the node type and its console before the node is sent to EVE-NG.
+// An empty console is left for EVE-NG to choose from the template.
+func (n *Node) Validate() error {
+	if n.Type == "" {
+		return errors.New("Node type is required")
+	}
+	if !n.Type.Valid() {
+		return errors.New("Invalid node type " + strconv.Quote(string(n.Type)))
+	}
+	if n.Console == "" {
+		return nil
+	}
+	if !n.Console.Valid() {
+		return errors.New("Invalid console type " + strconv.Quote(string(n.Console)))
+	}
+	for _, console := range nodeConsoles[n.Type] {
+		if console == n.Console {
+			return nil
+		}
+	}
+	return errors.New("Node type " + string(n.Type) + " does not support a " + string(n.Console) + " console")
+}
@@ -0,0 +1,298 @@
+package evengsdk
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// PoolMember is an EVE-NG server of a Pool.
+type PoolMember struct {
+	// Name identifies the server in the pool.
+	Name   string
+	Client *Client
+	// Labels describe the server for LabelAffinity (e.g. site: paris, gpu: yes).
+	Labels map[string]string
+	// Capacity is optional, when every server of a placement sets it LeastLoaded compares free memory instead of memory usage.
+	Capacity HostCapacity
+}
+
+// PlacementStrategy chooses the server of a new lab among the members of a pool.
+type PlacementStrategy interface {
+	Place(members []*PoolMember, lab string) (*PoolMember, error)
+}
+
+// LeastLoaded places labs on the server with the most free memory, according to the status endpoint.
+// The free memory is compared in MB when every server has a Capacity, in percent otherwise.
+// Servers whose status cannot be read are left out.
+type LeastLoaded struct{}
+
+// RoundRobin places labs on each server in turn, it has to be used as a pointer.
+type RoundRobin struct {
+	next atomic.Uint64
+}
+
+// LabelAffinity places labs on the servers having all the labels, using Fallback among them (LeastLoaded by default).
+type LabelAffinity struct {
+	Labels   map[string]string
+	Fallback PlacementStrategy
+}
+
+func (LeastLoaded) Place(members []*PoolMember, lab string) (*PoolMember, error) {
+	var available []*PoolMember
+	var free []float64
+	var errs []error
+	capacity := true
+	for _, member := range members {
+		usage, err := member.Client.GetHostUsage()
+		if err != nil {
+			errs = append(errs, errors.New(member.Name+": "+err.Error()))
+			continue
+		}
+		available = append(available, member)
+		free = append(free, 100-usage.Mem)
+		capacity = capacity && member.Capacity.Ram > 0
+	}
+	if len(available) == 0 {
+		return nil, errors.Join(append([]error{errors.New("No server available for " + lab)}, errs...)...)
+	}
+	// Free memory in MB is only comparable when every server has a capacity, otherwise the lowest memory usage wins.
+	var best *PoolMember
+	var bestScore float64
+	for i, member := range available {
+		score := free[i]
+		if capacity {
+			score = float64(member.Capacity.Ram) * free[i] / 100
+		}
+		if best == nil || score > bestScore {
+			best, bestScore = member, score
+		}
+	}
+	return best, nil
+}
+
+func (r *RoundRobin) Place(members []*PoolMember, lab string) (*PoolMember, error) {
+	if len(members) == 0 {
+		return nil, errors.New("No server available for " + lab)
+	}
+	return members[(r.next.Add(1)-1)%uint64(len(members))], nil
+}
+
+func (a LabelAffinity) Place(members []*PoolMember, lab string) (*PoolMember, error) {
+	var matching []*PoolMember
+	for _, member := range members {
+		if hasLabels(member.Labels, a.Labels) {
+			matching = append(matching, member)
+		}
+	}
+	if len(matching) == 0 {
+		return nil, errors.New("No server matches the labels of " + lab)
+	}
+	fallback := a.Fallback
+	if fallback == nil {
+		fallback = LeastLoaded{}
+	}
+	return fallback.Place(matching, lab)
+}
+
+func hasLabels(labels map[string]string, want map[string]string) bool {
+	for key, value := range want {
+		if v, ok := labels[key]; !ok || v != value {
+			return false
+		}
+	}
+	return true
+}
+
+// Pool spreads labs over several EVE-NG servers and routes the calls for a lab to the server holding it.
+type Pool struct {
+	members  []*PoolMember
+	strategy PlacementStrategy
+	lock     sync.Mutex
+	// labs maps lab paths to the server holding them.
+	labs map[string]*PoolMember
+}
+
+// NewPool returns a Pool of logged in clients, placing new labs with the strategy (LeastLoaded when nil).
+func NewPool(strategy PlacementStrategy, members ...*PoolMember) (*Pool, error) {
+	if len(members) == 0 {
+		return nil, errors.New("Pool needs at least one server")
+	}
+	names := make(map[string]bool, len(members))
+	for i, member := range members {
+		if member.Client == nil {
+			return nil, errors.New("Server " + strconv.Itoa(i) + " has no client")
+		}
+		if member.Name == "" {
+			member.Name = member.Client.BaseURL().Host
+		}
+		if names[member.Name] {
+			return nil, errors.New("Duplicate server name " + member.Name)
+		}
+		names[member.Name] = true
+	}
+	if strategy == nil {
+		strategy = LeastLoaded{}
+	}
+	return &Pool{members: members, strategy: strategy, labs: make(map[string]*PoolMember)}, nil
+}
+
+// Members returns the servers of the pool.
+func (p *Pool) Members() []*PoolMember {
+	return append([]*PoolMember(nil), p.members...)
+}
+
+// Member returns the server with the specified name.
+func (p *Pool) Member(name string) (*PoolMember, bool) {
+	for _, member := range p.members {
+		if member.Name == name {
+			return member, true
+		}
+	}
+	return nil, false
+}
+
+// Refresh rebuilds the lab assignments by listing the labs of every server.
+// Labs found on several servers are assigned to the first one, in the order the servers were given.
+func (p *Pool) Refresh() error {
+	labs := make(map[string]*PoolMember)
+	var errs []error
+	for _, member := range p.members {
+		paths, err := member.Client.Folder.GetLabs(context.Background(), "/")
+		if err != nil {
+			errs = append(errs, errors.New(member.Name+": "+err.Error()))
+			continue
+		}
+		for _, path := range paths {
+			if _, ok := labs[path]; !ok {
+				labs[path] = member
+			}
+		}
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if len(errs) > 0 {
+		// Keep the known assignments of the servers that could not be listed.
+		for path, member := range p.labs {
+			if _, ok := labs[path]; !ok {
+				labs[path] = member
+			}
+		}
+	}
+	p.labs = labs
+	return errors.Join(errs...)
+}
+
+// Assignments returns the name of the server holding each known lab, keyed by lab path.
+func (p *Pool) Assignments() map[string]string {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	assignments := make(map[string]string, len(p.labs))
+	for path, member := range p.labs {
+		assignments[path] = member.Name
+	}
+	return assignments
+}
+
+// Assign records that the lab with the specified path is on the named server, e.g. to restore saved Assignments.
+func (p *Pool) Assign(path string, name string) error {
+	member, ok := p.Member(name)
+	if !ok {
+		return errors.New("Server " + name + " not found")
+	}
+	p.lock.Lock()
+	p.labs[path] = member
+	p.lock.Unlock()
+	return nil
+}
+
+// Locate returns the server holding the lab with the specified path.
+// The path should be the full path to the lab file, including the extension (e.g. /path/to/labfile.unl).
+// Unknown labs are looked up on every server and remembered.
+func (p *Pool) Locate(path string) (*PoolMember, error) {
+	p.lock.Lock()
+	member, ok := p.labs[path]
+	p.lock.Unlock()
+	if ok {
+		return member, nil
+	}
+	for _, member := range p.members {
+		if _, err := member.Client.Lab.GetLab(path); err == nil {
+			p.lock.Lock()
+			p.labs[path] = member
+			p.lock.Unlock()
+			return member, nil
+		}
+	}
+	return nil, errors.New("Lab " + path + " not found on any server")
+}
+
+// Client returns the client of the server holding the lab with the specified path, see Locate.
+func (p *Pool) Client(path string) (*Client, error) {
+	member, err := p.Locate(path)
+	if err != nil {
+		return nil, err
+	}
+	return member.Client, nil
+}
+
+// Lab returns the LabService of the server holding the lab with the specified path, see Locate.
+func (p *Pool) Lab(path string) (*LabService, error) {
+	client, err := p.Client(path)
+	if err != nil {
+		return nil, err
+	}
+	return client.Lab, nil
+}
+
+// Node returns the NodeService of the server holding the lab with the specified path, see Locate.
+func (p *Pool) Node(path string) (*NodeService, error) {
+	client, err := p.Client(path)
+	if err != nil {
+		return nil, err
+	}
+	return client.Node, nil
+}
+
+// CreateLab places a new lab with the strategy, the one of the pool when nil, and creates it on the chosen server.
+// The path should be the full path to the lab file, including the extension (e.g. /path/to/labfile.unl).
+func (p *Pool) CreateLab(path string, lab Lab, strategy PlacementStrategy) (*PoolMember, error) {
+	p.lock.Lock()
+	_, exists := p.labs[path]
+	p.lock.Unlock()
+	if exists {
+		return nil, errors.New("Lab " + path + " already exists")
+	}
+	if strategy == nil {
+		strategy = p.strategy
+	}
+	member, err := strategy.Place(p.Members(), path)
+	if err != nil {
+		return nil, err
+	}
+	if err := member.Client.Lab.CreateLab(path, lab); err != nil {
+		return nil, errors.New(member.Name + ": " + err.Error())
+	}
+	p.lock.Lock()
+	p.labs[path] = member
+	p.lock.Unlock()
+	return member, nil
+}
+
+// DeleteLab deletes the lab with the specified path from the server holding it and forgets it.
+// The path should be the full path to the lab file, including the extension (e.g. /path/to/labfile.unl).
+func (p *Pool) DeleteLab(path string) error {
+	member, err := p.Locate(path)
+	if err != nil {
+		return err
+	}
+	if err := member.Client.Lab.DeleteLab(path); err != nil {
+		return err
+	}
+	p.lock.Lock()
+	delete(p.labs, path)
+	p.lock.Unlock()
+	return nil
+}
@@ -0,0 +1,201 @@
+package evengsdk
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ReadyStrategy decides when a started node is ready to be used.
+// WaitReady blocks until the node is ready or the context is done.
+type ReadyStrategy interface {
+	WaitReady(ctx context.Context, s *NodeService, path string, node *Node) error
+}
+
+// StatusReady polls the node until its Status is Want.
+type StatusReady struct {
+	// Want defaults to NodeStatusRunning.
+	Want NodeStatus
+	// Interval defaults to 2 seconds.
+	Interval time.Duration
+}
+
+// TCPReady waits until a TCP port of the node accepts connections.
+type TCPReady struct {
+	// Address is the host:port to connect to. The node console address is used when it is empty.
+	Address string
+	// Interval defaults to 2 seconds.
+	Interval time.Duration
+}
+
+// ReadySequence waits for each strategy in turn.
+type ReadySequence []ReadyStrategy
+
+// ReadyByTemplate selects the strategy by node template, nodes without an entry use Default.
+// Nodes are considered ready right away when no strategy applies.
+type ReadyByTemplate struct {
+	Strategies map[string]ReadyStrategy
+	Default    ReadyStrategy
+}
+
+// ReadyResult is the outcome of waiting for a single node.
+type ReadyResult struct {
+	NodeId   int
+	Name     string
+	Duration time.Duration
+	Err      error
+}
+
+// ReadyTimeoutError is returned by WaitAllReady when some nodes were not ready in time.
+type ReadyTimeoutError struct {
+	Pending []string
+	Elapsed time.Duration
+}
+
+func (e *ReadyTimeoutError) Error() string {
+	return "Timed out after " + e.Elapsed.Round(time.Second).String() + " waiting for nodes: " + strings.Join(e.Pending, ", ")
+}
+
+func (e *ReadyTimeoutError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// readyPoll calls check every interval until it returns true, an error, or the context is done.
+func readyPoll(ctx context.Context, interval time.Duration, check func() (bool, error)) error {
+	if interval == 0 {
+		interval = 2 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		ok, err := check()
+		if err != nil || ok {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r StatusReady) WaitReady(ctx context.Context, s *NodeService, path string, node *Node) error {
+	want := r.Want
+	if want == NodeStatusStopped {
+		want = NodeStatusRunning
+	}
+	return readyPoll(ctx, r.Interval, func() (bool, error) {
+		current, err := s.GetNode(path, node.Id)
+		if err != nil {
+			return false, err
+		}
+		*node = *current
+		return node.Status == want, nil
+	})
+}
+
+func (r TCPReady) WaitReady(ctx context.Context, s *NodeService, path string, node *Node) error {
+	address := r.Address
+	if address == "" {
+		host, port, err := node.ConsoleAddress()
+		if err != nil {
+			return err
+		}
+		address = net.JoinHostPort(host, strconv.Itoa(port))
+	}
+	return readyPoll(ctx, r.Interval, func() (bool, error) {
+		var dialer net.Dialer
+		dialCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		conn, err := dialer.DialContext(dialCtx, "tcp", address)
+		if err != nil {
+			return false, nil
+		}
+		conn.Close()
+		return true, nil
+	})
+}
+
+func (r ReadySequence) WaitReady(ctx context.Context, s *NodeService, path string, node *Node) error {
+	for _, strategy := range r {
+		if err := strategy.WaitReady(ctx, s, path, node); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r ReadyByTemplate) WaitReady(ctx context.Context, s *NodeService, path string, node *Node) error {
+	strategy, ok := r.Strategies[node.Template]
+	if !ok {
+		strategy = r.Default
+	}
+	if strategy == nil {
+		return nil
+	}
+	return strategy.WaitReady(ctx, s, path, node)
+}
+
+// WaitReady waits until the node with the specified id in the specified path is ready according to the strategy.
+// The path should be the full path to the lab file, including the extension (e.g. /path/to/labfile.unl).
+func (s *NodeService) WaitReady(ctx context.Context, path string, node int, strategy ReadyStrategy) error {
+	current, err := s.GetNode(path, node)
+	if err != nil {
+		return err
+	}
+	return strategy.WaitReady(ctx, s, path, current)
+}
+
+// WaitAllReady waits concurrently until every node in the specified path is ready according to the strategy.
+// The path should be the full path to the lab file, including the extension (e.g. /path/to/labfile.unl).
+// The results are sorted by node id. When the context expires first, the error is a *ReadyTimeoutError listing the nodes that were not ready.
+func (s *NodeService) WaitAllReady(ctx context.Context, path string, strategy ReadyStrategy) ([]ReadyResult, error) {
+	nodes, err := s.GetNodes(path)
+	if err != nil {
+		return nil, err
+	}
+	sorted := make([]Node, 0, len(nodes))
+	for _, node := range nodes {
+		sorted = append(sorted, node)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Id < sorted[j].Id })
+	return s.waitNodesReady(ctx, path, sorted, strategy)
+}
+
+func (s *NodeService) waitNodesReady(ctx context.Context, path string, nodes []Node, strategy ReadyStrategy) ([]ReadyResult, error) {
+	start := time.Now()
+	results := make([]ReadyResult, len(nodes))
+	var wg sync.WaitGroup
+	for i := range nodes {
+		results[i] = ReadyResult{NodeId: nodes[i].Id, Name: nodes[i].Name}
+		wg.Add(1)
+		go func(node Node, result *ReadyResult) {
+			defer wg.Done()
+			result.Err = strategy.WaitReady(ctx, s, path, &node)
+			result.Duration = time.Since(start)
+		}(nodes[i], &results[i])
+	}
+	wg.Wait()
+
+	var pending []string
+	var errs []error
+	for _, result := range results {
+		switch {
+		case result.Err == nil:
+		case errors.Is(result.Err, context.DeadlineExceeded):
+			pending = append(pending, result.Name)
+		default:
+			errs = append(errs, errors.New(result.Name+": "+result.Err.Error()))
+		}
+	}
+	if len(pending) > 0 {
+		return results, &ReadyTimeoutError{Pending: pending, Elapsed: time.Since(start)}
+	}
+	return results, errors.Join(errs...)
+}
@@ -0,0 +1,274 @@
+package evengsdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ReaperOptions configures a Reaper.
+type ReaperOptions struct {
+	// Root is the folder scanned recursively for labs. Defaults to "/".
+	Root string
+	// TTL is how long a lab may run before it is stopped. A TTL of 0 disables reaping.
+	TTL time.Duration
+	// FolderTTL overrides TTL for the labs in a folder and its subfolders (e.g. "/students": 2 * time.Hour), the longest folder wins.
+	FolderTTL map[string]time.Duration
+	// Allow lists the labs and folders that are never reaped.
+	Allow []string
+	// DryRun reports the labs that would be reaped without stopping them.
+	DryRun bool
+	// Wipe wipes the nodes of reaped labs after stopping them.
+	Wipe bool
+	// StateFile persists the time nodes were first seen running, so that restarting the reaper does not reset the TTLs.
+	// The state is only kept in memory when it is empty.
+	StateFile string
+}
+
+// TTLFor returns the TTL of the lab with the specified path and whether the lab may be reaped at all.
+func (o ReaperOptions) TTLFor(lab string) (time.Duration, bool) {
+	for _, allowed := range o.Allow {
+		if lab == allowed || inFolder(lab, allowed) {
+			return 0, false
+		}
+	}
+	ttl := o.TTL
+	longest := -1
+	for folder, folderTTL := range o.FolderTTL {
+		if inFolder(lab, folder) && len(folder) > longest {
+			ttl = folderTTL
+			longest = len(folder)
+		}
+	}
+	return ttl, ttl > 0
+}
+
+// inFolder reports whether the path is inside the folder or one of its subfolders.
+func inFolder(path string, folder string) bool {
+	folder = strings.TrimSuffix(folder, "/")
+	return strings.HasPrefix(path, folder+"/")
+}
+
+// ReapAction is a lab stopped by the reaper, or that would be with DryRun.
+type ReapAction struct {
+	Lab string
+	// RunningSince is the time the oldest running node of the lab was first seen running.
+	RunningSince time.Time
+	TTL          time.Duration
+	// Nodes are the ids of the running nodes.
+	Nodes  []int
+	DryRun bool
+	Wiped  bool
+	Err    error
+}
+
+// Reaper stops the labs that have been running for longer than their TTL.
+// Running times are tracked between scans, so they are only as precise as the scan interval.
+// Nodes already running when a lab is first scanned are considered started at that time.
+type Reaper struct {
+	client *Client
+	opts   ReaperOptions
+	lock   sync.Mutex
+	// running maps lab paths to the time each running node was first seen running, keyed by node id.
+	running map[string]map[int]time.Time
+}
+
+// NewReaper returns a Reaper, loading its state file when it exists.
+func NewReaper(client *Client, opts ReaperOptions) (*Reaper, error) {
+	if opts.Root == "" {
+		opts.Root = "/"
+	}
+	r := &Reaper{client: client, opts: opts, running: make(map[string]map[int]time.Time)}
+	if opts.StateFile == "" {
+		return r, nil
+	}
+	data, err := os.ReadFile(opts.StateFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &r.running); err != nil {
+		return nil, errors.New("Invalid reaper state file " + opts.StateFile + ": " + err.Error())
+	}
+	if r.running == nil {
+		r.running = make(map[string]map[int]time.Time)
+	}
+	return r, nil
+}
+
+// Scan checks every lab once, records the nodes running and reaps the labs over their TTL.
+// Errors on single labs are reported in their action or joined in the error, the scan goes on with the other labs.
+func (r *Reaper) Scan(ctx context.Context) ([]ReapAction, error) {
+	labs, err := r.client.Folder.GetLabs(ctx, r.opts.Root)
+	if err != nil {
+		return nil, err
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	now := time.Now()
+	var actions []ReapAction
+	var errs []error
+	seen := make(map[string]bool, len(labs))
+	for _, lab := range labs {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+		seen[lab] = true
+		nodes, err := r.client.Node.GetNodes(lab)
+		if err != nil {
+			errs = append(errs, errors.New(lab+": "+err.Error()))
+			continue
+		}
+		since, running := r.observe(lab, nodes, now)
+		ttl, ok := r.opts.TTLFor(lab)
+		if !ok || len(running) == 0 || now.Sub(since) < ttl {
+			continue
+		}
+		action := ReapAction{Lab: lab, RunningSince: since, TTL: ttl, Nodes: running, DryRun: r.opts.DryRun}
+		if !r.opts.DryRun {
+			action.Err = r.reap(ctx, lab, nodes, &action)
+			if action.Err == nil {
+				delete(r.running, lab)
+			}
+		}
+		actions = append(actions, action)
+	}
+	for lab := range r.running {
+		if !seen[lab] && ctx.Err() == nil {
+			delete(r.running, lab)
+		}
+	}
+	if err := r.save(); err != nil {
+		errs = append(errs, err)
+	}
+	return actions, errors.Join(errs...)
+}
+
+// observe updates the running times of the nodes of the lab and returns the oldest one with the ids of the running nodes.
+func (r *Reaper) observe(lab string, nodes map[string]Node, now time.Time) (time.Time, []int) {
+	previous := r.running[lab]
+	current := make(map[int]time.Time)
+	var since time.Time
+	var running []int
+	for _, node := range nodes {
+		if !node.Status.Running() {
+			continue
+		}
+		started, ok := previous[node.Id]
+		if !ok {
+			started = now
+		}
+		current[node.Id] = started
+		running = append(running, node.Id)
+		if since.IsZero() || started.Before(since) {
+			since = started
+		}
+	}
+	sort.Ints(running)
+	if len(current) == 0 {
+		delete(r.running, lab)
+	} else {
+		r.running[lab] = current
+	}
+	return since, running
+}
+
+// reap stops the nodes of the lab and wipes them when the options say so.
+func (r *Reaper) reap(ctx context.Context, lab string, nodes map[string]Node, action *ReapAction) error {
+	if _, err := r.client.Node.BulkStop(ctx, lab, action.Nodes, BulkOptions{}); err != nil {
+		return err
+	}
+	if !r.opts.Wipe {
+		return nil
+	}
+	ids := make([]int, 0, len(nodes))
+	for _, node := range nodes {
+		ids = append(ids, node.Id)
+	}
+	sort.Ints(ids)
+	if _, err := r.client.Node.BulkWipe(ctx, lab, ids, BulkOptions{}); err != nil {
+		return err
+	}
+	action.Wiped = true
+	return nil
+}
+
+// save writes the state file atomically.
+func (r *Reaper) save() error {
+	if r.opts.StateFile == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(r.running, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(r.opts.StateFile), filepath.Base(r.opts.StateFile)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), r.opts.StateFile)
+}
+
+// Run scans the labs every interval until the context is done, calling report for every lab reaped.
+// Scan errors are reported as actions without a lab. It returns the error of the context, or an error when the interval is not positive.
+func (r *Reaper) Run(ctx context.Context, interval time.Duration, report func(ReapAction)) error {
+	if interval <= 0 {
+		return errors.New("Reaper interval must be positive")
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		actions, err := r.Scan(ctx)
+		if report != nil {
+			for _, action := range actions {
+				report(action)
+			}
+			if err != nil && ctx.Err() == nil {
+				report(ReapAction{Err: err})
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// String describes the action for logs.
+func (a ReapAction) String() string {
+	if a.Lab == "" {
+		return "Reaper: " + a.Err.Error()
+	}
+	verb := "Stopped"
+	if a.Wiped {
+		verb = "Stopped and wiped"
+	}
+	if a.DryRun {
+		verb = "Would stop"
+	}
+	message := verb + " " + a.Lab + " (" + strconv.Itoa(len(a.Nodes)) + " nodes running since " + a.RunningSince.Format(time.RFC3339) + ", TTL " + a.TTL.String() + ")"
+	if a.Err != nil {
+		message += ": " + a.Err.Error()
+	}
+	return message
+}
@@ -0,0 +1,297 @@
+package evengsdk
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Topology holds everything needed to draw a lab: its nodes, its networks and the links between them.
+type Topology struct {
+	Nodes    map[string]Node
+	Networks map[string]Network
+	Links    []TopologyLink
+}
+
+// TopologyLink is a single entry of the api/labs/{lab}/topology endpoint.
+// Source and Destination are endpoint identifiers such as "node1" or "network3".
+type TopologyLink struct {
+	Type             string
+	Source           string
+	SourceType       string
+	SourceLabel      string
+	Destination      string
+	DestinationType  string
+	DestinationLabel string
+	NetworkId        int
+}
+
+// RenderOptions controls how a Topology is rendered.
+type RenderOptions struct {
+	// Name is used as the graph name. Defaults to "lab".
+	Name string
+	// CollapseBridges draws bridge networks with exactly two attached interfaces as a direct edge between the nodes.
+	// Hidden networks with two interfaces, as created by LinkService.Connect, are always drawn that way.
+	CollapseBridges bool
+	// KeepPositions keeps the Left/Top coordinates of nodes and networks as layout hints.
+	// Graphviz receives them as pinned "pos" attributes (use neato -n), Mermaid receives the vertices ordered by position.
+	KeepPositions bool
+}
+
+// GetLabTopology returns the nodes, networks and links of the lab with the specified path.
+// The path should be the full path to the lab file, including the extension (e.g. /path/to/labfile.unl).
+func (s *LabService) GetLabTopology(path string) (*Topology, error) {
+	nodes, err := s.client.Node.GetNodes(path)
+	if err != nil {
+		return nil, err
+	}
+	networks, err := s.client.Network.GetNetworks(path)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := s.GetTopology(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Topology{
+		Nodes:    nodes,
+		Networks: networks,
+		Links:    ParseTopologyLinks(raw),
+	}, nil
+}
+
+// ParseTopologyLinks converts the raw result of GetTopology into typed links.
+func ParseTopologyLinks(raw []map[string]interface{}) []TopologyLink {
+	links := make([]TopologyLink, 0, len(raw))
+	for _, entry := range raw {
+		links = append(links, TopologyLink{
+			Type:             topologyString(entry["type"]),
+			Source:           topologyString(entry["source"]),
+			SourceType:       topologyString(entry["source_type"]),
+			SourceLabel:      topologyString(entry["source_label"]),
+			Destination:      topologyString(entry["destination"]),
+			DestinationType:  topologyString(entry["destination_type"]),
+			DestinationLabel: topologyString(entry["destination_label"]),
+			NetworkId:        topologyInt(entry["network_id"]),
+		})
+	}
+	return links
+}
+
+// topologyString and topologyInt tolerate the loosely typed values returned by the topology endpoint.
+func topologyString(v interface{}) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	}
+	return ""
+}
+
+func topologyInt(v interface{}) int {
+	switch t := v.(type) {
+	case float64:
+		return int(t)
+	case string:
+		i, _ := strconv.Atoi(t)
+		return i
+	}
+	return 0
+}
+
+// topologyId returns the numeric id of an endpoint such as "node1" or "network3".
+func topologyId(endpoint string) int {
+	id, _ := strconv.Atoi(strings.TrimLeft(endpoint, "abcdefghijklmnopqrstuvwxyz"))
+	return id
+}
+
+type renderVertex struct {
+	id        string
+	label     []string
+	network   bool
+	left, top int
+}
+
+type renderEdge struct {
+	from, to           string
+	fromLabel, toLabel string
+}
+
+type renderGraph struct {
+	vertices []renderVertex
+	edges    []renderEdge
+}
+
+func newRenderGraph(t *Topology, opts RenderOptions) *renderGraph {
+	g := &renderGraph{}
+	attached := make(map[string][]TopologyLink)
+	for _, link := range t.Links {
+		if link.SourceType == "node" && link.DestinationType == "network" {
+			attached[link.Destination] = append(attached[link.Destination], link)
+		}
+	}
+	// Hidden networks with two interfaces are the bridges behind direct node links, they are always drawn as an edge.
+	collapsed := make(map[string][]TopologyLink)
+	for endpoint, links := range attached {
+		network, ok := t.Networks[strconv.Itoa(topologyId(endpoint))]
+		if ok && len(links) == 2 && (network.Visibility == "0" || opts.CollapseBridges && network.Type == NetworkBridge) {
+			collapsed[endpoint] = links
+		}
+	}
+
+	for _, node := range t.Nodes {
+		g.vertices = append(g.vertices, renderVertex{
+			id:    "node" + strconv.Itoa(node.Id),
+			label: []string{node.Name, node.Template},
+			left:  node.Left,
+			top:   node.Top,
+		})
+	}
+	for _, network := range t.Networks {
+		endpoint := "network" + strconv.Itoa(network.Id)
+		if _, ok := collapsed[endpoint]; ok {
+			continue
+		}
+		// Other hidden networks are only drawn when an interface is attached to them.
+		if _, ok := attached[endpoint]; !ok && network.Visibility == "0" {
+			continue
+		}
+		g.vertices = append(g.vertices, renderVertex{
+			id:      endpoint,
+			label:   []string{network.Name},
+			network: true,
+			left:    network.Left,
+			top:     network.Top,
+		})
+	}
+	sort.Slice(g.vertices, func(i, j int) bool {
+		a, b := g.vertices[i], g.vertices[j]
+		if opts.KeepPositions && (a.top != b.top || a.left != b.left) {
+			if a.top != b.top {
+				return a.top < b.top
+			}
+			return a.left < b.left
+		}
+		if a.network != b.network {
+			return !a.network
+		}
+		return topologyId(a.id) < topologyId(b.id)
+	})
+
+	for _, link := range t.Links {
+		if _, ok := collapsed[link.Destination]; ok {
+			continue
+		}
+		g.edges = append(g.edges, renderEdge{
+			from:      link.Source,
+			to:        link.Destination,
+			fromLabel: link.SourceLabel,
+			toLabel:   link.DestinationLabel,
+		})
+	}
+	for _, links := range collapsed {
+		g.edges = append(g.edges, renderEdge{
+			from:      links[0].Source,
+			to:        links[1].Source,
+			fromLabel: links[0].SourceLabel,
+			toLabel:   links[1].SourceLabel,
+		})
+	}
+	sort.Slice(g.edges, func(i, j int) bool {
+		a, b := g.edges[i], g.edges[j]
+		if a.from != b.from {
+			return a.from < b.from
+		}
+		if a.fromLabel != b.fromLabel {
+			return a.fromLabel < b.fromLabel
+		}
+		return a.to < b.to
+	})
+	return g
+}
+
+// RenderDOT writes the topology as a Graphviz DOT graph.
+// Nodes are labeled with their name and template, edges with the interface names on each side.
+func RenderDOT(w io.Writer, t *Topology, opts RenderOptions) error {
+	name := opts.Name
+	if name == "" {
+		name = "lab"
+	}
+	g := newRenderGraph(t, opts)
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "graph %s {\n", dotQuote(name))
+	fmt.Fprintf(bw, "  node [shape=box];\n")
+	for _, v := range g.vertices {
+		attrs := []string{"label=" + dotQuote(strings.Join(v.label, "\n"))}
+		if v.network {
+			attrs = append(attrs, "shape=ellipse")
+		}
+		if opts.KeepPositions {
+			// EVE-NG measures Top downwards while Graphviz measures y upwards.
+			attrs = append(attrs, fmt.Sprintf("pos=\"%d,%d!\"", v.left, -v.top))
+		}
+		fmt.Fprintf(bw, "  %s [%s];\n", dotQuote(v.id), strings.Join(attrs, ", "))
+	}
+	for _, e := range g.edges {
+		var attrs []string
+		if e.fromLabel != "" {
+			attrs = append(attrs, "taillabel="+dotQuote(e.fromLabel))
+		}
+		if e.toLabel != "" {
+			attrs = append(attrs, "headlabel="+dotQuote(e.toLabel))
+		}
+		if len(attrs) > 0 {
+			fmt.Fprintf(bw, "  %s -- %s [%s];\n", dotQuote(e.from), dotQuote(e.to), strings.Join(attrs, ", "))
+		} else {
+			fmt.Fprintf(bw, "  %s -- %s;\n", dotQuote(e.from), dotQuote(e.to))
+		}
+	}
+	fmt.Fprintf(bw, "}\n")
+	return bw.Flush()
+}
+
+// RenderMermaid writes the topology as a Mermaid flowchart.
+// Nodes are labeled with their name and template, edges with the interface names on each side.
+func RenderMermaid(w io.Writer, t *Topology, opts RenderOptions) error {
+	g := newRenderGraph(t, opts)
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "flowchart LR\n")
+	for _, v := range g.vertices {
+		label := mermaidQuote(strings.Join(v.label, "<br/>"))
+		if v.network {
+			fmt.Fprintf(bw, "  %s((%s))\n", v.id, label)
+		} else {
+			fmt.Fprintf(bw, "  %s[%s]\n", v.id, label)
+		}
+	}
+	for _, e := range g.edges {
+		label := e.fromLabel
+		if e.toLabel != "" {
+			if label != "" {
+				label += " - "
+			}
+			label += e.toLabel
+		}
+		if label != "" {
+			fmt.Fprintf(bw, "  %s ---|%s| %s\n", e.from, mermaidQuote(label), e.to)
+		} else {
+			fmt.Fprintf(bw, "  %s --- %s\n", e.from, e.to)
+		}
+	}
+	return bw.Flush()
+}
+
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
+
+func mermaidQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, "#quot;") + `"`
+}
@@ -0,0 +1,261 @@
+package evengsdk
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// HostCapacity is the size of the EVE-NG host. The status endpoint only reports usage as percentages, so the totals have to be known.
+type HostCapacity struct {
+	// Ram is the memory of the host in MB.
+	Ram int
+	// Cpu is the number of CPUs of the host. When it is not set, only the memory is checked.
+	Cpu int
+	// Overcommit is the number of node CPUs allowed per free host CPU. Defaults to 1.
+	Overcommit float64
+}
+
+// HostUsage is the load of the EVE-NG host as reported by the status endpoint, in percent.
+type HostUsage struct {
+	Cpu  float64
+	Mem  float64
+	Swap float64
+	Disk float64
+}
+
+// NodeResources are the resources a node needs to run, with template defaults applied.
+type NodeResources struct {
+	NodeId int
+	Name   string
+	// Ram is in MB.
+	Ram int
+	Cpu int
+	// Running nodes already use their resources and are not counted in ResourceReport.Ram and ResourceReport.Cpu.
+	Running bool
+}
+
+// ResourceReport compares the resources needed by the stopped nodes of a lab with the free resources of the host.
+type ResourceReport struct {
+	// Nodes are sorted by id.
+	Nodes []NodeResources
+	// Ram and Cpu are the resources needed to start the stopped nodes.
+	Ram int
+	Cpu int
+	// FreeRam and FreeCpu are derived from the host capacity and usage, FreeCpu includes the overcommit.
+	FreeRam  int
+	FreeCpu  int
+	Capacity HostCapacity
+	Usage    HostUsage
+}
+
+// Fits reports whether the stopped nodes can all be started.
+func (r *ResourceReport) Fits() bool {
+	return r.Ram <= r.FreeRam && (!r.checksCpu() || r.Cpu <= r.FreeCpu)
+}
+
+// checksCpu reports whether the host capacity includes the CPUs, otherwise only the memory is checked.
+func (r *ResourceReport) checksCpu() bool {
+	return r.Capacity.Cpu > 0
+}
+
+// ResourceError is returned by StartNodesWithOptions when the host does not have enough resources for the lab.
+type ResourceError struct {
+	Report *ResourceReport
+	// Skipped are the nodes left stopped, it is empty when nothing was started.
+	Skipped []NodeResources
+}
+
+func (e *ResourceError) Error() string {
+	var message string
+	if e.Report.checksCpu() {
+		message = "Insufficient resources: need " + strconv.Itoa(e.Report.Ram) + " MB RAM and " + strconv.Itoa(e.Report.Cpu) + " CPU, " +
+			strconv.Itoa(e.Report.FreeRam) + " MB RAM and " + strconv.Itoa(e.Report.FreeCpu) + " CPU free"
+	} else {
+		message = "Insufficient resources: need " + strconv.Itoa(e.Report.Ram) + " MB RAM, " + strconv.Itoa(e.Report.FreeRam) + " MB RAM free"
+	}
+	if len(e.Skipped) == 0 {
+		return message
+	}
+	names := make([]string, len(e.Skipped))
+	for i, node := range e.Skipped {
+		names[i] = node.Name
+	}
+	return message + ", not started: " + strings.Join(names, ", ")
+}
+
+// CapacityPolicy is what StartNodesWithOptions does when the host does not have enough resources for the lab.
+type CapacityPolicy int
+
+const (
+	// CapacityRefuse starts no node.
+	CapacityRefuse CapacityPolicy = iota
+	// CapacitySubset starts the nodes that fit, by priority.
+	CapacitySubset
+)
+
+// StartOptions configures StartNodesWithOptions.
+type StartOptions struct {
+	// Capacity is required, at least its Ram.
+	Capacity HostCapacity
+	// Policy defaults to CapacityRefuse.
+	Policy CapacityPolicy
+	// Priority ranks the nodes started by CapacitySubset, higher first and then by id. All nodes have the same priority by default.
+	Priority func(node Node) int
+}
+
+// GetHostUsage returns the CPU, memory, swap and disk usage of the EVE-NG host.
+func (c *Client) GetHostUsage() (*HostUsage, error) {
+	status, err := c.GetStatus()
+	if err != nil {
+		return nil, err
+	}
+	var usage HostUsage
+	for key, value := range map[string]*float64{"cpu": &usage.Cpu, "mem": &usage.Mem, "swap": &usage.Swap, "disk": &usage.Disk} {
+		percent, err := strconv.ParseFloat(templateValue(status[key]), 64)
+		if err != nil {
+			return nil, errors.New("Unexpected " + key + " usage in status")
+		}
+		*value = percent
+	}
+	return &usage, nil
+}
+
+// CheckResources compares the resources needed by the stopped nodes with the free resources of the host.
+// Nodes that do not set Ram or Cpu use the defaults of their template.
+// The capacity must set the memory of the host, the CPUs are only checked when it sets them too.
+func (s *NodeService) CheckResources(nodes []Node, capacity HostCapacity) (*ResourceReport, error) {
+	if capacity.Ram <= 0 {
+		return nil, errors.New("Host capacity is required")
+	}
+	var names []string
+	seen := make(map[string]bool)
+	for _, node := range nodes {
+		if (node.Ram == 0 || node.Cpu == 0) && !seen[node.Template] {
+			seen[node.Template] = true
+			names = append(names, node.Template)
+		}
+	}
+	templates, errs := s.getTemplates(names)
+	if len(errs) > 0 {
+		return nil, joinTemplateErrors(errs)
+	}
+	usage, err := s.client.GetHostUsage()
+	if err != nil {
+		return nil, err
+	}
+	return CheckNodeResources(nodes, templates, *usage, capacity), nil
+}
+
+// CheckResources compares the resources needed by the stopped nodes in the specified path with the free resources of the host,
+// see NodeService.CheckResources.
+// The path should be the full path to the lab file, including the extension (e.g. /path/to/labfile.unl).
+func (s *LabService) CheckResources(path string, capacity HostCapacity) (*ResourceReport, error) {
+	nodes, err := s.client.Node.GetNodes(path)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]Node, 0, len(nodes))
+	for _, node := range nodes {
+		list = append(list, node)
+	}
+	return s.client.Node.CheckResources(list, capacity)
+}
+
+// CheckNodeResources builds the resource report of the nodes from their templates, keyed by template name, and the host usage.
+func CheckNodeResources(nodes []Node, templates map[string]*Template, usage HostUsage, capacity HostCapacity) *ResourceReport {
+	if capacity.Overcommit <= 0 {
+		capacity.Overcommit = 1
+	}
+	report := &ResourceReport{
+		FreeRam:  int(float64(capacity.Ram) * (100 - usage.Mem) / 100),
+		FreeCpu:  int(float64(capacity.Cpu) * (100 - usage.Cpu) / 100 * capacity.Overcommit),
+		Capacity: capacity,
+		Usage:    usage,
+	}
+	for _, node := range nodes {
+		resources := NodeResources{NodeId: node.Id, Name: node.Name, Ram: node.Ram, Cpu: node.Cpu, Running: node.Status.Running()}
+		if template := templates[node.Template]; template != nil {
+			if resources.Ram == 0 {
+				resources.Ram, _ = strconv.Atoi(template.Options["ram"].Value)
+			}
+			if resources.Cpu == 0 {
+				resources.Cpu, _ = strconv.Atoi(template.Options["cpu"].Value)
+			}
+		}
+		if !resources.Running {
+			report.Ram += resources.Ram
+			report.Cpu += resources.Cpu
+		}
+		report.Nodes = append(report.Nodes, resources)
+	}
+	sort.Slice(report.Nodes, func(i, j int) bool { return report.Nodes[i].NodeId < report.Nodes[j].NodeId })
+	return report
+}
+
+// Subset returns the stopped nodes to start within the free resources, by decreasing priority, and the ones left out.
+// Nodes that do not fit are skipped so that smaller nodes of lower priority can still start. priority may be nil.
+func (r *ResourceReport) Subset(priority func(id int) int) (start []NodeResources, skipped []NodeResources) {
+	var stopped []NodeResources
+	for _, node := range r.Nodes {
+		if !node.Running {
+			stopped = append(stopped, node)
+		}
+	}
+	if priority != nil {
+		sort.SliceStable(stopped, func(i, j int) bool { return priority(stopped[i].NodeId) > priority(stopped[j].NodeId) })
+	}
+	ram, cpu := r.FreeRam, r.FreeCpu
+	for _, node := range stopped {
+		if node.Ram > ram || (r.checksCpu() && node.Cpu > cpu) {
+			skipped = append(skipped, node)
+			continue
+		}
+		ram -= node.Ram
+		cpu -= node.Cpu
+		start = append(start, node)
+	}
+	return start, skipped
+}
+
+// StartNodesWithOptions starts all nodes in the specified path after checking that the host has the resources to run them.
+// The path should be the full path to the lab file, including the extension (e.g. /path/to/labfile.unl).
+// The options must set the host capacity. If the host is too small, the error is a *ResourceError and opts.Policy
+// decides which nodes were started.
+func (s *NodeService) StartNodesWithOptions(path string, opts StartOptions) error {
+	if opts.Capacity.Ram <= 0 {
+		return errors.New("Host capacity is required")
+	}
+	nodes, err := s.GetNodes(path)
+	if err != nil {
+		return err
+	}
+	list := make([]Node, 0, len(nodes))
+	byId := make(map[int]Node, len(nodes))
+	for _, node := range nodes {
+		list = append(list, node)
+		byId[node.Id] = node
+	}
+	report, err := s.CheckResources(list, opts.Capacity)
+	if err != nil {
+		return err
+	}
+	if report.Fits() {
+		return s.StartNodes(path)
+	}
+	if opts.Policy != CapacitySubset {
+		return &ResourceError{Report: report}
+	}
+	var priority func(id int) int
+	if opts.Priority != nil {
+		priority = func(id int) int { return opts.Priority(byId[id]) }
+	}
+	start, skipped := report.Subset(priority)
+	for _, node := range start {
+		if err := s.StartNode(path, node.NodeId); err != nil {
+			return err
+		}
+	}
+	return &ResourceError{Report: report, Skipped: skipped}
+}
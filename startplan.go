@@ -0,0 +1,222 @@
+package evengsdk
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StartPlanOptions configures PlanStart, StartOrdered and StopOrdered.
+type StartPlanOptions struct {
+	// Dependencies maps a node name to the names of the nodes that must be started before it (e.g. PE1: [RR1, RR2]).
+	Dependencies map[string][]string
+	// Wave optionally puts nodes in a wave, nodes are still placed after the waves of their dependencies.
+	Wave func(node Node) int
+	// Concurrency is the number of nodes started or stopped at the same time in a wave. Defaults to 4.
+	Concurrency int
+	// Ready enables readiness gating, the next wave starts once every node of the current wave is ready.
+	Ready ReadyStrategy
+	// ReadyTimeout bounds the wait for each wave. No timeout by default.
+	ReadyTimeout time.Duration
+	// IgnoreDelay starts nodes without waiting for their Delay.
+	IgnoreDelay bool
+}
+
+// StartWave is a group of nodes started together, sorted by delay and then by id.
+type StartWave struct {
+	Index int
+	Nodes []Node
+}
+
+// PlanStart splits the nodes into waves so that every node comes after the nodes it depends on.
+// Without dependencies nor Wave, all nodes are in a single wave.
+func PlanStart(nodes []Node, opts StartPlanOptions) ([]StartWave, error) {
+	byName := make(map[string][]int)
+	for i, node := range nodes {
+		byName[node.Name] = append(byName[node.Name], i)
+	}
+	for name, deps := range opts.Dependencies {
+		if _, ok := byName[name]; !ok {
+			return nil, errors.New("Node " + name + " not found")
+		}
+		for _, dep := range deps {
+			if _, ok := byName[dep]; !ok {
+				return nil, errors.New("Dependency " + dep + " of " + name + " not found")
+			}
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(nodes))
+	waves := make([]int, len(nodes))
+	var visit func(i int, chain []string) error
+	visit = func(i int, chain []string) error {
+		chain = append(chain, nodes[i].Name)
+		switch state[i] {
+		case visiting:
+			return errors.New("Dependency cycle: " + strings.Join(chain, " -> "))
+		case visited:
+			return nil
+		}
+		state[i] = visiting
+		if opts.Wave != nil {
+			waves[i] = opts.Wave(nodes[i])
+		}
+		for _, dep := range opts.Dependencies[nodes[i].Name] {
+			for _, j := range byName[dep] {
+				if err := visit(j, chain); err != nil {
+					return err
+				}
+				if waves[j]+1 > waves[i] {
+					waves[i] = waves[j] + 1
+				}
+			}
+		}
+		state[i] = visited
+		return nil
+	}
+	for i := range nodes {
+		if err := visit(i, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	byWave := make(map[int][]Node)
+	for i, node := range nodes {
+		byWave[waves[i]] = append(byWave[waves[i]], node)
+	}
+	plan := make([]StartWave, 0, len(byWave))
+	for index, nodes := range byWave {
+		sort.Slice(nodes, func(i, j int) bool {
+			if nodes[i].Delay != nodes[j].Delay {
+				return nodes[i].Delay < nodes[j].Delay
+			}
+			return nodes[i].Id < nodes[j].Id
+		})
+		plan = append(plan, StartWave{Index: index, Nodes: nodes})
+	}
+	sort.Slice(plan, func(i, j int) bool { return plan[i].Index < plan[j].Index })
+	return plan, nil
+}
+
+// planLab returns the start plan of the nodes in the specified path.
+func (s *NodeService) planLab(path string, opts StartPlanOptions) ([]StartWave, error) {
+	nodes, err := s.GetNodes(path)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]Node, 0, len(nodes))
+	for _, node := range nodes {
+		list = append(list, node)
+	}
+	return PlanStart(list, opts)
+}
+
+// runWave calls action for every node of the wave, at most concurrency at a time.
+// When delay is true, each node waits for its Delay in seconds from the start of the wave.
+func runWave(ctx context.Context, wave StartWave, concurrency int, delay bool, action func(node Node) error) error {
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+	start := time.Now()
+	sem := make(chan struct{}, concurrency)
+	var lock sync.Mutex
+	var errs []error
+	var wg sync.WaitGroup
+	for _, node := range wave.Nodes {
+		if delay && node.Delay > 0 {
+			select {
+			case <-time.After(time.Until(start.Add(time.Duration(node.Delay) * time.Second))):
+			case <-ctx.Done():
+			}
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(node Node) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := action(node); err != nil {
+				lock.Lock()
+				errs = append(errs, errors.New(node.Name+": "+err.Error()))
+				lock.Unlock()
+			}
+		}(node)
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// StartOrdered starts the nodes in the specified path wave by wave, see PlanStart.
+// The path should be the full path to the lab file, including the extension (e.g. /path/to/labfile.unl).
+// Nodes of a wave wait for their Delay, and with a Ready strategy the next wave only starts once the previous one is ready.
+// It stops at the first wave with an error, leaving the later waves stopped.
+func (s *NodeService) StartOrdered(ctx context.Context, path string, opts StartPlanOptions) error {
+	plan, err := s.planLab(path, opts)
+	if err != nil {
+		return err
+	}
+	for i, wave := range plan {
+		err := runWave(ctx, wave, opts.Concurrency, !opts.IgnoreDelay, func(node Node) error {
+			return s.StartNode(path, node.Id)
+		})
+		if err != nil {
+			return err
+		}
+		if opts.Ready == nil || i == len(plan)-1 {
+			continue
+		}
+		if err := s.waitWaveReady(ctx, path, wave, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *NodeService) waitWaveReady(ctx context.Context, path string, wave StartWave, opts StartPlanOptions) error {
+	if opts.ReadyTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.ReadyTimeout)
+		defer cancel()
+	}
+	_, err := s.waitNodesReady(ctx, path, wave.Nodes, opts.Ready)
+	return err
+}
+
+// StopOrdered stops the nodes in the specified path wave by wave, in the reverse order of StartOrdered.
+// The path should be the full path to the lab file, including the extension (e.g. /path/to/labfile.unl).
+// Delays and readiness are not waited for.
+func (s *NodeService) StopOrdered(ctx context.Context, path string, opts StartPlanOptions) error {
+	plan, err := s.planLab(path, opts)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for i := len(plan) - 1; i >= 0; i-- {
+		err := runWave(ctx, plan[i], opts.Concurrency, false, func(node Node) error {
+			return s.StopNode(path, node.Id)
+		})
+		if err != nil {
+			errs = append(errs, err)
+			if ctx.Err() != nil {
+				break
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
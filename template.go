@@ -0,0 +1,235 @@
+package evengsdk
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Template is a node template as listed by EVE-NG (e.g. vios).
+type Template struct {
+	Name        string
+	Description string
+	Type        NodeType
+	// Options are keyed by node field name (e.g. ram, image, console).
+	Options map[string]TemplateOption
+}
+
+// TemplateOption is a setting of the nodes created from a template.
+type TemplateOption struct {
+	// Name is the label shown by EVE-NG (e.g. RAM (MB)).
+	Name string
+	// Type is the kind of input, "list" options only accept the values of List.
+	Type string
+	// Value is the default value, numbers are formatted in base 10.
+	Value string
+	// List holds the allowed values of list options, sorted.
+	List []string
+	// Min and Max bound numeric options, Max is 0 when there is no upper bound.
+	Min int
+	Max int
+}
+
+// templateMin are the lower bounds of the numeric options EVE-NG does not bound itself.
+var templateMin = map[string]int{"cpu": 1, "ram": 1}
+
+func (t *Template) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Description string                    `json:"description"`
+		Type        string                    `json:"type"`
+		Options     map[string]TemplateOption `json:"options"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	t.Description = raw.Description
+	t.Type = NodeType(raw.Type)
+	t.Options = raw.Options
+	if t.Options == nil {
+		t.Options = make(map[string]TemplateOption)
+	}
+	if t.Type == "" {
+		t.Type = NodeType(t.Options["type"].Value)
+	}
+	for key, option := range t.Options {
+		if option.Min == 0 {
+			option.Min = templateMin[key]
+			t.Options[key] = option
+		}
+	}
+	return nil
+}
+
+// UnmarshalJSON accepts values of any JSON type and lists given either as an object, keyed by value, or as an array.
+func (o *TemplateOption) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Name  string          `json:"name"`
+		Type  string          `json:"type"`
+		Value interface{}     `json:"value"`
+		List  json.RawMessage `json:"list"`
+		Min   interface{}     `json:"min"`
+		Max   interface{}     `json:"max"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*o = TemplateOption{Name: raw.Name, Type: raw.Type, Value: templateValue(raw.Value)}
+	o.Min, _ = strconv.Atoi(templateValue(raw.Min))
+	o.Max, _ = strconv.Atoi(templateValue(raw.Max))
+	if len(raw.List) == 0 {
+		return nil
+	}
+	var object map[string]interface{}
+	if err := json.Unmarshal(raw.List, &object); err == nil {
+		for value := range object {
+			o.List = append(o.List, value)
+		}
+	} else {
+		var array []interface{}
+		if err := json.Unmarshal(raw.List, &array); err != nil {
+			return err
+		}
+		for _, value := range array {
+			o.List = append(o.List, templateValue(value))
+		}
+	}
+	sort.Strings(o.List)
+	return nil
+}
+
+func templateValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		if v {
+			return "1"
+		}
+		return "0"
+	}
+	return ""
+}
+
+// Images returns the images installed for the template.
+func (t *Template) Images() []string {
+	return t.Options["image"].List
+}
+
+// Check returns an error when value is not allowed for the option.
+func (o TemplateOption) Check(value string) error {
+	if o.Type == "list" {
+		i := sort.SearchStrings(o.List, value)
+		if i == len(o.List) || o.List[i] != value {
+			return errors.New("Invalid " + o.Name + " " + strconv.Quote(value) + ", expected one of " + strings.Join(o.List, ", "))
+		}
+		return nil
+	}
+	if o.Min == 0 && o.Max == 0 {
+		return nil
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return errors.New("Invalid " + o.Name + " " + strconv.Quote(value) + ", expected a number")
+	}
+	if number < o.Min || (o.Max != 0 && number > o.Max) {
+		bounds := strconv.Itoa(o.Min) + " or more"
+		if o.Max != 0 {
+			bounds = "between " + strconv.Itoa(o.Min) + " and " + strconv.Itoa(o.Max)
+		}
+		return errors.New("Invalid " + o.Name + " " + value + ", expected " + bounds)
+	}
+	return nil
+}
+
+// NewNode returns a node of the template, with the fields left empty in overrides set to the template defaults.
+// The fields set in overrides are checked against the values allowed by the template, as is the resulting node with Node.Validate.
+// Options without a Node field are stored in Extra.
+func (t *Template) NewNode(overrides Node) (*Node, error) {
+	node := overrides
+	node.Extra = make(map[string]json.RawMessage, len(overrides.Extra))
+	for key, value := range overrides.Extra {
+		node.Extra[key] = value
+	}
+	node.Template = t.Name
+	if node.Type == "" {
+		node.Type = t.Type
+	}
+	v := reflect.ValueOf(&node).Elem()
+	keys := make([]string, 0, len(t.Options))
+	for key := range t.Options {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var errs []error
+	for _, key := range keys {
+		option := t.Options[key]
+		if key == "template" || key == "type" {
+			continue
+		}
+		index, ok := nodeFields[key]
+		if !ok {
+			if _, set := node.Extra[key]; !set && option.Value != "" {
+				node.Extra[key], _ = json.Marshal(option.Value)
+			}
+			continue
+		}
+		field := v.FieldByIndex(index)
+		if !field.IsZero() {
+			if err := option.Check(templateFieldString(field)); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		if option.Value == "" {
+			continue
+		}
+		switch field.Kind() {
+		case reflect.String:
+			if _, isNumber := field.Interface().(json.Number); isNumber {
+				if _, err := strconv.ParseFloat(option.Value, 64); err != nil {
+					continue
+				}
+			}
+			field.SetString(option.Value)
+		case reflect.Int:
+			number, err := strconv.Atoi(option.Value)
+			if err != nil {
+				errs = append(errs, errors.New("Invalid default "+option.Name+" "+strconv.Quote(option.Value)))
+				continue
+			}
+			field.SetInt(int64(number))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	if len(node.Extra) == 0 {
+		node.Extra = nil
+	}
+	if err := node.Validate(); err != nil {
+		return nil, err
+	}
+	return &node, nil
+}
+
+func templateFieldString(field reflect.Value) string {
+	if field.Kind() == reflect.Int {
+		return strconv.FormatInt(field.Int(), 10)
+	}
+	return field.String()
+}
+
+// NewNodeFromTemplate returns a node of the template with the specified name, ready to be passed to CreateNode.
+// The fields left empty in overrides are set to the template defaults, see Template.NewNode.
+func (s *NodeService) NewNodeFromTemplate(template string, overrides Node) (*Node, error) {
+	t, err := s.GetTemplate(template)
+	if err != nil {
+		return nil, err
+	}
+	return t.NewNode(overrides)
+}
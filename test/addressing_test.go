@@ -0,0 +1,221 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/CorentinPtrl/evengsdk"
+	"net/netip"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func addressingLab() (map[string]evengsdk.Node, map[string]evengsdk.Network, []evengsdk.Attachment) {
+	nodes := map[string]evengsdk.Node{
+		"1": {Id: 1, Name: "R1"},
+		"2": {Id: 2, Name: "R2"},
+		"3": {Id: 3, Name: "R3"},
+	}
+	networks := map[string]evengsdk.Network{
+		"1": {Id: 1, Type: "bridge"},
+		"2": {Id: 2, Type: "bridge"},
+		"3": {Id: 3, Type: "pnet0"},
+	}
+	attachments := []evengsdk.Attachment{
+		{NodeId: 2, Interface: "Gi0/0", NetworkId: 1},
+		{NodeId: 1, Interface: "Gi0/0", NetworkId: 1},
+		{NodeId: 1, Interface: "Gi0/1", NetworkId: 2},
+		{NodeId: 2, Interface: "Gi0/1", NetworkId: 2},
+		{NodeId: 3, Interface: "Gi0/1", NetworkId: 2},
+		{NodeId: 3, Interface: "Gi0/0", NetworkId: 3},
+	}
+	return nodes, networks, attachments
+}
+
+func TestPlanAddresses(t *testing.T) {
+	nodes, networks, attachments := addressingLab()
+	plan, err := evengsdk.PlanAddresses(nodes, networks, attachments, evengsdk.DefaultAddressPools(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Loopbacks) != 3 || plan.Loopbacks[2].IPv4.String() != "10.255.255.3/32" || plan.Loopbacks[2].IPv6.String() != "fd00:0:0:ffff::3/128" {
+		t.Fatalf("unexpected loopbacks: %v", plan.Loopbacks)
+	}
+	if len(plan.Links) != 2 {
+		t.Fatalf("expected 2 links, got %d", len(plan.Links))
+	}
+	p2p := plan.Links[0]
+	if p2p.Kind != evengsdk.LinkKindP2P || p2p.IPv4.String() != "10.0.0.0/31" {
+		t.Fatalf("unexpected p2p link: %v", p2p)
+	}
+	if p2p.Interfaces[0].Node != "R1" || p2p.Interfaces[0].IPv4.String() != "10.0.0.0/31" || p2p.Interfaces[1].IPv4.String() != "10.0.0.1/31" {
+		t.Fatalf("unexpected p2p interfaces: %v", p2p.Interfaces)
+	}
+	lan := plan.Links[1]
+	if lan.Kind != evengsdk.LinkKindLan || lan.IPv4.String() != "10.1.1.0/24" || lan.Interfaces[2].IPv4.String() != "10.1.1.3/24" {
+		t.Fatalf("unexpected lan link: %v", lan)
+	}
+	if lan.Interfaces[2].IPv6.String() != "fd00:0:1:1::3/64" {
+		t.Fatalf("unexpected lan IPv6 address: %v", lan.Interfaces[2].IPv6)
+	}
+
+	again, err := evengsdk.PlanAddresses(nodes, networks, attachments, evengsdk.DefaultAddressPools(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var a, b bytes.Buffer
+	plan.WriteJSON(&a)
+	again.WriteJSON(&b)
+	if a.String() != b.String() {
+		t.Fatal("Plan is not deterministic")
+	}
+}
+
+func TestPlanAddresses_Previous(t *testing.T) {
+	nodes, networks, attachments := addressingLab()
+	pools := evengsdk.DefaultAddressPools()
+	pools.P2PBits4 = 30
+	// A link without a network takes the first free slot, which would be the slot of network 1 later on.
+	serial := []evengsdk.Attachment{
+		{NodeId: 1, Interface: "s1/0", Link: "serial"},
+		{NodeId: 3, Interface: "s1/0", Link: "serial"},
+	}
+	first, err := evengsdk.PlanAddresses(nodes, networks, serial, pools, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Links[0].IPv4.String() != "10.0.0.0/30" || first.Links[0].Interfaces[0].IPv4.String() != "10.0.0.1/30" {
+		t.Fatalf("unexpected link: %v", first.Links[0])
+	}
+	var buf bytes.Buffer
+	err = first.WriteJSON(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var previous evengsdk.AddressPlan
+	err = json.Unmarshal(buf.Bytes(), &previous)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := evengsdk.PlanAddresses(nodes, networks, append(attachments, serial...), pools, &previous)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, link := range second.Links {
+		switch link.Key {
+		case "link:serial":
+			if link.IPv4.String() != "10.0.0.0/30" {
+				t.Fatalf("serial link was renumbered: %v", link.IPv4)
+			}
+		case "network1":
+			if link.IPv4.String() == "10.0.0.0/30" {
+				t.Fatal("network1 overlaps the serial link")
+			}
+		}
+	}
+}
+
+func TestPlanAddresses_LoopbackPool(t *testing.T) {
+	pools := evengsdk.DefaultAddressPools()
+	pools.Loopback4 = netip.MustParsePrefix("10.255.255.0/30")
+	nodes := map[string]evengsdk.Node{"1": {Id: 1, Name: "R1"}, "2": {Id: 2, Name: "R2"}}
+	plan, err := evengsdk.PlanAddresses(nodes, nil, nil, pools, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.Loopbacks[0].IPv4.String() != "10.255.255.1/32" || plan.Loopbacks[1].IPv4.String() != "10.255.255.2/32" {
+		t.Fatalf("unexpected loopbacks: %v", plan.Loopbacks)
+	}
+	// A previous plan holding the broadcast address is not kept.
+	previous := &evengsdk.AddressPlan{Loopbacks: []evengsdk.LoopbackAddress{{NodeId: 2, IPv4: netip.MustParsePrefix("10.255.255.3/32")}}}
+	plan, err = evengsdk.PlanAddresses(nodes, nil, nil, pools, previous)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.Loopbacks[1].IPv4.String() != "10.255.255.2/32" {
+		t.Fatalf("unexpected loopbacks: %v", plan.Loopbacks)
+	}
+	// Node ids past the pool exhaust it instead of wrapping to the network address.
+	for _, id := range []int{3, 4} {
+		nodes := map[string]evengsdk.Node{"1": {Id: id, Name: "R1"}}
+		if _, err := evengsdk.PlanAddresses(nodes, nil, nil, pools, nil); err == nil || !strings.Contains(err.Error(), "exhausted") {
+			t.Fatalf("node %d: expected an exhausted pool, got %v", id, err)
+		}
+	}
+}
+
+func TestAddressPlan_Export(t *testing.T) {
+	nodes, networks, attachments := addressingLab()
+	plan, err := evengsdk.PlanAddresses(nodes, networks, attachments, evengsdk.DefaultAddressPools(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	err = plan.WriteCSV(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), "kind,link,node_id,node,interface,ipv4,ipv6\nloopback,,1,R1,,10.255.255.1/32,fd00:0:0:ffff::1/128\n") {
+		t.Fatalf("unexpected csv:\n%s", buf.String())
+	}
+	if !strings.Contains(buf.String(), "p2p,network1,2,R2,Gi0/0,10.0.0.1/31,fd00:0:0:1::1/127\n") {
+		t.Fatalf("unexpected csv:\n%s", buf.String())
+	}
+	vars := plan.TemplateVars()
+	if vars["R3"]["loopback4"] != "10.255.255.3/32" {
+		t.Fatalf("unexpected vars: %v", vars["R3"])
+	}
+	interfaces := vars["R3"]["interfaces"].(map[string]map[string]string)
+	if interfaces["Gi0/1"]["ipv4"] != "10.1.1.3/24" {
+		t.Fatalf("unexpected vars: %v", interfaces)
+	}
+}
+
+func TestLabService_PlanAddresses(t *testing.T) {
+	client, err := evengsdk.NewBasicAuthClient(os.Getenv("EVE_USER"), os.Getenv("EVE_PASSWORD"), "0", os.Getenv("EVE_HOST"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	time := time.Now()
+	err = client.Lab.CreateLab("/"+time.Format("15-04-05")+".unl", evengsdk.Lab{
+		Description: "Unit Test",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Lab.DeleteLab("/" + time.Format("15-04-05") + ".unl")
+	network := &evengsdk.Network{
+		Name:       "Test",
+		Type:       "bridge",
+		Visibility: "1",
+		Icon:       "lan.png",
+	}
+	err = client.Network.CreateNetwork("/"+time.Format("15-04-05")+".unl", network)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		node := &evengsdk.Node{
+			Ethernet: 2,
+			Name:     "vpc",
+			Template: "vpcs",
+			Type:     "qemu",
+		}
+		err = client.Node.CreateNode("/"+time.Format("15-04-05")+".unl", node)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = client.Node.UpdateNodeInterface("/"+time.Format("15-04-05")+".unl", node.Id, 0, network.Id)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	plan, err := client.Lab.PlanAddresses("/"+time.Format("15-04-05")+".unl", evengsdk.DefaultAddressPools(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Links) != 1 {
+		t.Fatal("Link was not planned")
+	}
+}
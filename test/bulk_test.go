@@ -0,0 +1,138 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/CorentinPtrl/evengsdk"
+	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunBulk(t *testing.T) {
+	ids := []int{1, 2, 3, 4, 5, 6}
+	var running, peak int32
+	action := func(id int) error {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		if id%3 == 0 {
+			return errors.New("failed")
+		}
+		return nil
+	}
+	result, err := evengsdk.RunBulk(context.Background(), ids, evengsdk.BulkOptions{Concurrency: 2}, action)
+	if err == nil || len(result.Items) != len(ids) || peak > 2 {
+		t.Fatalf("unexpected result %+v, peak %d", result, peak)
+	}
+	failed := result.Failed()
+	if len(failed) != 2 || failed[0].NodeId != 3 || failed[1].NodeId != 6 || failed[0].Skipped {
+		t.Fatalf("unexpected failures %+v", failed)
+	}
+	for _, item := range result.Items {
+		if item.Ok() && item.Duration == 0 {
+			t.Fatalf("missing duration %+v", item)
+		}
+	}
+
+	result, err = evengsdk.RunBulk(context.Background(), ids, evengsdk.BulkOptions{Concurrency: 1, Mode: evengsdk.FailFast}, action)
+	if err == nil {
+		t.Fatal("Should have failed")
+	}
+	if !result.Items[1].Ok() || result.Items[2].Ok() || result.Items[2].Skipped || !result.Items[3].Skipped || !result.Items[5].Skipped {
+		t.Fatalf("unexpected result %+v", result.Items)
+	}
+
+	result, err = evengsdk.RunBulk(context.Background(), []int{1, 2}, evengsdk.BulkOptions{}, func(id int) error { return nil })
+	if err != nil || len(result.Failed()) != 0 {
+		t.Fatalf("unexpected result %+v, %v", result, err)
+	}
+}
+
+func TestNodeService_BulkUpdate_Changes(t *testing.T) {
+	var bodies []map[string]interface{}
+	client := apiServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && strings.HasSuffix(r.URL.Path, "/test.unl/nodes/1"):
+			apiResponse(w, map[string]interface{}{"name": "R1", "type": "qemu", "template": "vios", "status": 0, "ram": 1024, "cpulimit": 1, "qemu_options": "-nographic"})
+		case r.Method == "PUT" && strings.HasSuffix(r.URL.Path, "/test.unl/nodes/1"):
+			var body map[string]interface{}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Error(err)
+			}
+			bodies = append(bodies, body)
+			apiResponse(w, nil)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	// As listed by GetNodes, without the settings, and running.
+	node := evengsdk.Node{Id: 1, Name: "R1", Type: evengsdk.NodeTypeQemu, Template: "vios", Status: evengsdk.NodeStatusRunning, Ram: 2048}
+	result, err := client.Node.BulkUpdate(context.Background(), "/test.unl", []evengsdk.Node{node}, evengsdk.BulkOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Err() != nil {
+		t.Fatal(result.Err())
+	}
+	if len(bodies) != 1 || len(bodies[0]) != 1 || bodies[0]["ram"] != 2048.0 {
+		t.Fatalf("only the ram should be sent, got %v", bodies)
+	}
+}
+
+func TestNodeService_Bulk(t *testing.T) {
+	client, err := evengsdk.NewBasicAuthClient(os.Getenv("EVE_USER"), os.Getenv("EVE_PASSWORD"), "0", os.Getenv("EVE_HOST"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lab := "/" + time.Now().Format("15-04-05") + ".unl"
+	err = client.Lab.CreateLab(lab, evengsdk.Lab{Name: lab[1 : len(lab)-4], Description: "Bulk operations"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Lab.DeleteLab(lab)
+	var ids []int
+	for _, name := range []string{"PC1", "PC2", "PC3"} {
+		node := &evengsdk.Node{Name: name, Template: "vpcs", Type: evengsdk.NodeTypeVpcs, Console: evengsdk.ConsoleTelnet}
+		if err := client.Node.CreateNode(lab, node); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, node.Id)
+	}
+	ctx := context.Background()
+	if _, err := client.Node.BulkStart(ctx, lab, ids, evengsdk.BulkOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Node.BulkStop(ctx, lab, ids, evengsdk.BulkOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Node.BulkWipe(ctx, lab, ids, evengsdk.BulkOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	nodes, err := client.Node.GetNodes(lab)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var updates []evengsdk.Node
+	for _, node := range nodes {
+		node.Left += 50
+		updates = append(updates, node)
+	}
+	if _, err := client.Node.BulkUpdate(ctx, lab, updates, evengsdk.BulkOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	result, err := client.Node.BulkDelete(ctx, lab, append(ids, 999), evengsdk.BulkOptions{})
+	if err == nil || len(result.Failed()) != 1 || result.Failed()[0].NodeId != 999 {
+		t.Fatalf("unexpected result %+v, %v", result, err)
+	}
+}
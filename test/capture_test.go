@@ -0,0 +1,137 @@
+package test
+
+import (
+	"context"
+	"github.com/CorentinPtrl/evengsdk"
+	"github.com/CorentinPtrl/evengsdk/console"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestConsole_Capture(t *testing.T) {
+	// The console drops after the boot messages, as it does when the node restarts.
+	var connections atomic.Int32
+	addr := consoleServer(t, func(conn net.Conn) {
+		if connections.Add(1) == 1 {
+			conn.Write([]byte("Booting\r\nLoading image\r\n"))
+			return
+		}
+		conn.Write([]byte("Router>"))
+		time.Sleep(time.Second)
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Millisecond)
+	defer cancel()
+	dir := t.TempDir()
+	var out strings.Builder
+	node := &evengsdk.Node{Id: 1, Name: "R1", Console: "telnet", Url: "telnet://" + addr}
+	err := console.Capture(ctx, node, console.CaptureOptions{
+		Dir:        dir,
+		MaxSize:    40,
+		MaxFiles:   1,
+		Writer:     &out,
+		Reconnect:  50 * time.Millisecond,
+		FlushAfter: 100 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	line := regexp.MustCompile(`^\d{4}-\d\d-\d\dT\d\d:\d\d:\d\d\.\d{3}\S* R1: (.*)$`)
+	var lines []string
+	for _, l := range strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n") {
+		match := line.FindStringSubmatch(l)
+		if match == nil {
+			t.Fatalf("unexpected line %q", l)
+		}
+		lines = append(lines, match[1])
+	}
+	if strings.Join(lines, "|") != "Booting|Loading image|Router>" {
+		t.Fatalf("unexpected lines %q", lines)
+	}
+	current, err := os.ReadFile(filepath.Join(dir, "R1_1.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rotated, err := os.ReadFile(filepath.Join(dir, "R1_1.log.1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(string(current), " Router>\n") || !strings.HasSuffix(string(rotated), " Loading image\n") {
+		t.Fatalf("unexpected log files %q %q", current, rotated)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "R1_1.log.2")); !os.IsNotExist(err) {
+		t.Fatal("Too many rotated files")
+	}
+}
+
+func TestConsole_CaptureFileNames(t *testing.T) {
+	addr := consoleServer(t, func(conn net.Conn) {
+		conn.Write([]byte("Router>\r\n"))
+		time.Sleep(time.Second)
+	})
+	dir := t.TempDir()
+	// Both names sanitize to R_1, the node ids keep the transcripts apart.
+	for i, name := range []string{"R/1", "R:1"} {
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		node := &evengsdk.Node{Id: i + 1, Name: name, Console: "telnet", Url: "telnet://" + addr}
+		err := console.Capture(ctx, node, console.CaptureOptions{Dir: dir, Reconnect: 50 * time.Millisecond})
+		cancel()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, file := range []string{"R_1_1.log", "R_1_2.log"} {
+		data, err := os.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasSuffix(string(data), " Router>\n") {
+			t.Fatalf("unexpected log file %s %q", file, data)
+		}
+	}
+}
+
+func TestConsole_CaptureLab(t *testing.T) {
+	client, err := evengsdk.NewBasicAuthClient(os.Getenv("EVE_USER"), os.Getenv("EVE_PASSWORD"), "0", os.Getenv("EVE_HOST"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lab := "/" + time.Now().Format("15-04-05") + ".unl"
+	err = client.Lab.CreateLab(lab, evengsdk.Lab{
+		Description: "Unit Test",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Lab.DeleteLab(lab)
+	node := &evengsdk.Node{
+		Name:     "vpc",
+		Template: "vpcs",
+		Type:     "vpcs",
+	}
+	err = client.Node.CreateNode(lab, node)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = client.Node.StartNodes(lab)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Node.StopNodes(lab)
+	dir := t.TempDir()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	err = console.CaptureLab(ctx, client, lab, console.CaptureOptions{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "vpc_"+strconv.Itoa(node.Id)+".log")); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,118 @@
+package test
+
+import (
+	"github.com/CorentinPtrl/evengsdk"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+const configTemplate = `hostname {{ .Hostname }}
+interface Loopback0
+ ip address {{ .RouterId }} 255.255.255.255
+{{- range .Interfaces }}
+interface {{ .Name }}
+ ip address {{ .Address }} {{ netmask .Prefix }}
+{{- range .Peers }}
+ description to {{ .Node }} {{ .Interface }}
+{{- end }}
+{{- end }}
+`
+
+func TestConfigRenderer_Render(t *testing.T) {
+	renderer := evengsdk.NewConfigRenderer()
+	err := renderer.AddTemplate("vios", configTemplate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	topology := renderTopology()
+	topology.Nodes["3"] = evengsdk.Node{Id: 3, Name: "PC1", Template: "vpcs"}
+	results, err := renderer.Render(topology)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+	if results[0].Err != nil {
+		t.Fatal(results[0].Err)
+	}
+	for _, want := range []string{
+		"hostname R1\n",
+		" ip address 10.255.255.1 255.255.255.255",
+		"interface Gi0/0\n ip address 10.0.0.0 255.255.255.254\n description to R2 Gi0/1",
+	} {
+		if !strings.Contains(results[0].Config, want) {
+			t.Fatalf("missing %q in:\n%s", want, results[0].Config)
+		}
+	}
+	if strings.Contains(results[0].Config, "Gi0/2") {
+		t.Fatalf("management cloud should not be addressed:\n%s", results[0].Config)
+	}
+	if !strings.Contains(results[1].Config, "interface Gi0/1\n ip address 10.0.0.1 255.255.255.254") {
+		t.Fatalf("unexpected config:\n%s", results[1].Config)
+	}
+	if !results[2].Skipped {
+		t.Fatal("PC1 should be skipped")
+	}
+}
+
+func TestConfigRenderer_AddTemplate(t *testing.T) {
+	renderer := evengsdk.NewConfigRenderer()
+	err := renderer.AddTemplate("vios", "{{ .Hostname ")
+	if err == nil {
+		t.Fatal("Should have failed")
+	}
+}
+
+func TestNodeService_PushConfigs(t *testing.T) {
+	client, err := evengsdk.NewBasicAuthClient(os.Getenv("EVE_USER"), os.Getenv("EVE_PASSWORD"), "0", os.Getenv("EVE_HOST"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	time := time.Now()
+	err = client.Lab.CreateLab("/"+time.Format("15-04-05")+".unl", evengsdk.Lab{
+		Description: "Unit Test",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Lab.DeleteLab("/" + time.Format("15-04-05") + ".unl")
+	node := &evengsdk.Node{
+		Cpu:      1,
+		Delay:    0,
+		Ethernet: 8,
+		Image:    "viosl2-adventerprisek9-m.03.2017",
+		Left:     0,
+		Name:     "Switch_Test",
+		Ram:      1024,
+		Template: "viosl2",
+		Top:      0,
+		Config:   "1",
+		Type:     "qemu",
+	}
+	err = client.Node.CreateNode("/"+time.Format("15-04-05")+".unl", node)
+	if err != nil {
+		t.Fatal(err)
+	}
+	renderer := evengsdk.NewConfigRenderer()
+	err = renderer.AddTemplate("viosl2", "hostname {{ .Hostname }}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := client.Node.PushConfigs("/"+time.Format("15-04-05")+".unl", renderer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Err != nil {
+		t.Fatal("Config was not pushed")
+	}
+	config, err := client.Node.GetNodeConfig("/"+time.Format("15-04-05")+".unl", node.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config != "hostname Switch_Test" {
+		t.Fatal("Config is not correct")
+	}
+}
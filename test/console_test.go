@@ -0,0 +1,190 @@
+package test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"github.com/CorentinPtrl/evengsdk"
+	"github.com/CorentinPtrl/evengsdk/console"
+	"io"
+	"net"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// consoleServer is a local stand-in for a node console.
+// handler is called with the accepted connection for every client.
+func consoleServer(t *testing.T, handler func(net.Conn)) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				handler(conn)
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+// routerConsole answers like a router prompt, echoing every line and printing the prompt again.
+func routerConsole(prompt string) func(net.Conn) {
+	return func(conn net.Conn) {
+		conn.Write([]byte("\r\nUser Access Verification\r\n\r\n" + prompt))
+		reader := bufio.NewReader(conn)
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			if line == "enable" {
+				prompt = strings.TrimSuffix(prompt, ">") + "#"
+			}
+			conn.Write([]byte(line + "\r\n" + prompt))
+		}
+	}
+}
+
+func TestConsole_Negotiation(t *testing.T) {
+	replies := make(chan []byte, 1)
+	addr := consoleServer(t, func(conn net.Conn) {
+		// IAC WILL ECHO, IAC WILL SGA, IAC DO TERMINAL-TYPE, then data containing an escaped IAC and CR NUL.
+		conn.Write([]byte{255, 251, 1, 255, 251, 3, 255, 253, 24, 'o', 'k', 255, 255, '\r', 0, '\n'})
+		buf := make([]byte, 9)
+		io.ReadFull(conn, buf)
+		replies <- buf
+		conn.Write([]byte("done\r\n"))
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, err := console.Dial(ctx, "telnet://"+addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	line, err := conn.ReadLine(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if line != "ok\xff" {
+		t.Fatalf("unexpected line %q", line)
+	}
+	reply := <-replies
+	if !bytes.Equal(reply, []byte{255, 253, 1, 255, 253, 3, 255, 252, 24}) {
+		t.Fatalf("unexpected negotiation %v", reply)
+	}
+	line, err = conn.ReadLine(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if line != "done" {
+		t.Fatalf("unexpected line %q", line)
+	}
+}
+
+func TestConsole_NegotiationRepeated(t *testing.T) {
+	replies := make(chan []byte, 1)
+	addr := consoleServer(t, func(conn net.Conn) {
+		// Options sent again, enabled or refused, are only answered once.
+		conn.Write([]byte{255, 251, 1, 255, 251, 1, 255, 253, 3, 255, 253, 3, 255, 251, 24, 'o', 'k', '\r', '\n'})
+		conn.SetReadDeadline(time.Now().Add(500 * time.Millisecond))
+		buf, _ := io.ReadAll(conn)
+		replies <- buf
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, err := console.Dial(ctx, addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if line, err := conn.ReadLine(ctx); err != nil || line != "ok" {
+		t.Fatalf("unexpected line %q: %v", line, err)
+	}
+	reply := <-replies
+	if !bytes.Equal(reply, []byte{255, 253, 1, 255, 251, 3, 255, 254, 24}) {
+		t.Fatalf("unexpected negotiation %v", reply)
+	}
+}
+
+func TestConsole_SendLineExpect(t *testing.T) {
+	addr := consoleServer(t, routerConsole("Router>"))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, err := console.Dial(ctx, addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	out, err := conn.Expect(ctx, regexp.MustCompile(`Router>$`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "User Access Verification") {
+		t.Fatalf("unexpected output %q", out)
+	}
+	err = conn.SendLine(ctx, "enable")
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err = conn.Expect(ctx, regexp.MustCompile(`Router#$`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "enable\r\nRouter#" {
+		t.Fatalf("unexpected output %q", out)
+	}
+}
+
+func TestConsole_ExpectTimeout(t *testing.T) {
+	addr := consoleServer(t, routerConsole("Router>"))
+	conn, err := console.Dial(context.Background(), addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	_, err = conn.Expect(ctx, regexp.MustCompile(`Password:`))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected a deadline error, got %v", err)
+	}
+	// The connection is still usable after a timeout.
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err = conn.Expect(ctx, regexp.MustCompile(`Router>$`))
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConsole_Open(t *testing.T) {
+	addr := consoleServer(t, routerConsole("Switch>"))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, err := console.Open(ctx, &evengsdk.Node{Name: "Switch", Console: "telnet", Url: "telnet://" + addr})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_, err = conn.Expect(ctx, regexp.MustCompile(`Switch>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = console.Open(ctx, &evengsdk.Node{Name: "PC", Console: "vnc", Url: "vnc://" + addr})
+	if err == nil {
+		t.Fatal("Should have failed")
+	}
+}
@@ -1,11 +1,46 @@
 package test
 
 import (
+	"encoding/json"
 	"github.com/CorentinPtrl/evengsdk"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 )
 
+// apiServer starts a fake EVE-NG API accepting any login and returns a client logged in to it.
+// The requests other than the login and the status are passed to handler.
+func apiServer(t *testing.T, handler http.HandlerFunc) *evengsdk.Client {
+	return statusServer(t, map[string]interface{}{"version": "6.2.0-4"}, handler)
+}
+
+// statusServer is apiServer with the data returned by the status endpoint, which must hold a version.
+func statusServer(t *testing.T, status map[string]interface{}, handler http.HandlerFunc) *evengsdk.Client {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/auth/login":
+			apiResponse(w, nil)
+		case "/api/status":
+			apiResponse(w, status)
+		default:
+			handler(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	client, err := evengsdk.NewBasicAuthClient("admin", "eve", "0", server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+// apiResponse writes a successful API response holding data.
+func apiResponse(w http.ResponseWriter, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(evengsdk.Response{Code: "200", Status: "success", Message: "OK", Data: data})
+}
+
 func TestClient_GetAuth(t *testing.T) {
 	client, err := evengsdk.NewBasicAuthClient(os.Getenv("EVE_USER"), os.Getenv("EVE_PASSWORD"), "0", os.Getenv("EVE_HOST"))
 	if err != nil {
@@ -0,0 +1,105 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"github.com/CorentinPtrl/evengsdk"
+	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestIconService_ValidateNetwork(t *testing.T) {
+	var lists atomic.Int32
+	client := apiServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/list/icons":
+			lists.Add(1)
+			apiResponse(w, map[string]interface{}{"Router.png": "Router"})
+		case "/images/icons/lan.png", "/images/icons/Custom-Net.png":
+			w.Header().Set("Content-Type", "image/png")
+			w.Write([]byte("\x89PNG"))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	for _, icon := range []string{"", "Router.png", "lan.png", "Custom-Net.png", "lan.png"} {
+		if err := client.Icon.ValidateNetwork(icon); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := client.Icon.ValidateNetwork("missing.png"); err == nil {
+		t.Fatal("Should have failed")
+	}
+	if err := client.Icon.Validate("lan.png"); err == nil {
+		t.Fatal("Network icons are not node icons")
+	}
+	if lists.Load() != 1 {
+		t.Fatalf("Icon list fetched %d times", lists.Load())
+	}
+	client.Icon.InvalidateCache()
+	if err := client.Icon.Validate("Router.png"); err != nil {
+		t.Fatal(err)
+	}
+	if lists.Load() != 2 {
+		t.Fatal("Icon list not fetched again after InvalidateCache")
+	}
+}
+
+func TestIconService_Download(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	client := apiServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/images/icons/Router.png" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(png)
+	})
+	var buf bytes.Buffer
+	contentType, err := client.Icon.Download(context.Background(), "Router.png", &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if contentType != "image/png" || !bytes.Equal(buf.Bytes(), png) {
+		t.Fatalf("unexpected icon %s %q", contentType, buf.Bytes())
+	}
+	_, err = client.Icon.Download(context.Background(), "missing.png", &buf)
+	if err == nil {
+		t.Fatal("Should have failed")
+	}
+}
+
+func TestIconService_GetIcons(t *testing.T) {
+	client, err := evengsdk.NewBasicAuthClient(os.Getenv("EVE_USER"), os.Getenv("EVE_PASSWORD"), "0", os.Getenv("EVE_HOST"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	icons, err := client.Icon.GetIcons()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(icons) == 0 {
+		t.Fatal("No icons")
+	}
+	if err := client.Icon.Validate(icons[0].Name); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Icon.Validate("missing-icon.png"); err == nil {
+		t.Fatal("Should have failed")
+	}
+	var buf bytes.Buffer
+	contentType, err := client.Icon.Download(context.Background(), icons[0].Name, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() == 0 || !strings.HasPrefix(contentType, "image/") {
+		t.Fatalf("unexpected icon %s of %d bytes", contentType, buf.Len())
+	}
+	err = client.Node.CreateNode("/missing.unl", &evengsdk.Node{Name: "R1", Template: "vpcs", Type: evengsdk.NodeTypeVpcs, Icon: "missing-icon.png"})
+	if err == nil {
+		t.Fatal("Should have failed")
+	}
+}
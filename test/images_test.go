@@ -0,0 +1,93 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/CorentinPtrl/evengsdk"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCheckNodeImages(t *testing.T) {
+	var vios evengsdk.Template
+	if err := json.Unmarshal([]byte(viosTemplate), &vios); err != nil {
+		t.Fatal(err)
+	}
+	templates := map[string]*evengsdk.Template{
+		"vios": &vios,
+		"vpcs": {Name: "vpcs", Type: evengsdk.NodeTypeVpcs, Options: map[string]evengsdk.TemplateOption{}},
+		"csr1000v": {Name: "csr1000v", Type: evengsdk.NodeTypeQemu, Options: map[string]evengsdk.TemplateOption{
+			"image": {Name: "Image", Type: "list"},
+		}},
+	}
+	nodes := []evengsdk.Node{
+		{Id: 1, Name: "R1", Template: "vios"},
+		{Id: 2, Name: "R2", Template: "vios", Image: "vios-adventerprisek9-m-15.9.3M6"},
+		{Id: 3, Name: "PC", Template: "vpcs"},
+		{Id: 4, Name: "R3", Template: "vios", Image: "vios-missing"},
+		{Id: 5, Name: "CSR", Template: "csr1000v"},
+		{Id: 6, Name: "FW", Template: "asav"},
+	}
+	err := evengsdk.CheckNodeImages(nodes, templates)
+	var checkErr *evengsdk.ImageCheckError
+	if !errors.As(err, &checkErr) {
+		t.Fatalf("expected an image check error, got %v", err)
+	}
+	if len(checkErr.Problems) != 3 {
+		t.Fatalf("unexpected problems %v", checkErr.Problems)
+	}
+	for i, name := range []string{"R3", "CSR", "FW"} {
+		if checkErr.Problems[i].Name != name {
+			t.Fatalf("unexpected problems %v", checkErr.Problems)
+		}
+	}
+	if err := evengsdk.CheckNodeImages(nodes[:3], templates); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNodeService_GetImages_Partial(t *testing.T) {
+	client := apiServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/list/templates/":
+			apiResponse(w, map[string]string{"vios": "Cisco vIOS Router", "asav": "Cisco ASAv"})
+		case "/api/list/templates/vios":
+			apiResponse(w, json.RawMessage(viosTemplate))
+		default:
+			// Dropping the connection fails the request without retrying it.
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err == nil {
+				conn.Close()
+			}
+		}
+	})
+	images, err := client.Node.GetImages()
+	if err == nil || !strings.Contains(err.Error(), "asav: ") {
+		t.Fatalf("expected an error for asav, got %v", err)
+	}
+	if len(images) != 1 || images[0].Template != "vios" || len(images[0].Images) == 0 {
+		t.Fatalf("unexpected images %v", images)
+	}
+}
+
+func TestNodeService_GetImages(t *testing.T) {
+	client, err := evengsdk.NewBasicAuthClient(os.Getenv("EVE_USER"), os.Getenv("EVE_PASSWORD"), "0", os.Getenv("EVE_HOST"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	images, err := client.Node.GetImages()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, template := range images {
+		if template.Template == "vpcs" {
+			t.Fatal("vpcs does not use images")
+		}
+	}
+	err = client.Node.CheckImages([]evengsdk.Node{{Name: "R1", Template: "viosl2", Image: "viosl2-missing"}})
+	if err == nil {
+		t.Fatal("Should have failed")
+	}
+}
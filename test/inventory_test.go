@@ -0,0 +1,156 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/CorentinPtrl/evengsdk"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func inventoryNodes() map[string]evengsdk.Node {
+	return map[string]evengsdk.Node{
+		"1": {Id: 1, Name: "R1", Template: "vios", Type: "qemu", Console: "telnet", Url: "telnet://10.0.0.1:32769", Uuid: "uuid-1", Image: "vios-159"},
+		"2": {Id: 2, Name: "SW1", Template: "viosl2", Type: "qemu", Console: "telnet", Url: "telnet://10.0.0.1:32770"},
+		"3": {Id: 3, Name: "PC1", Template: "vpcs", Type: "vpcs", Url: "/html5/#/client/abc"},
+	}
+}
+
+func TestInventory_WriteYAML(t *testing.T) {
+	interfaces := map[int]*evengsdk.Interfaces{
+		1: {Ethernet: evengsdk.InterfaceEntry{0: {Name: "Gi0/0"}}},
+	}
+	inventory := evengsdk.NewInventory(inventoryNodes(), interfaces, evengsdk.InventoryOptions{})
+	var buf bytes.Buffer
+	err := inventory.WriteYAML(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"all:\n  children:\n    vios:\n      hosts:\n        R1:\n",
+		`          eve_console_host: "10.0.0.1"`,
+		"          eve_console_port: 32769",
+		`          eve_mgmt_interface: "Gi0/0"`,
+		`          eve_uuid: "uuid-1"`,
+		"    vpcs:\n      hosts:\n        PC1:\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("missing %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "ansible_host") || strings.Contains(out, "ansible_port") {
+		t.Fatal("The telnet console should not be used as the ssh connection")
+	}
+	if strings.Contains(out, "PC1:\n          eve_console_host") {
+		t.Fatal("PC1 should not have a console host")
+	}
+}
+
+func TestInventory_UniqueHosts(t *testing.T) {
+	nodes := map[string]evengsdk.Node{
+		"1": {Id: 1, Name: "R1", Template: "vios"},
+		"2": {Id: 2, Name: "R1", Template: "vios"},
+		"3": {Id: 3, Name: "R1_2", Template: "vios"},
+		"4": {Id: 4, Name: "", Template: "vios"},
+	}
+	inventory := evengsdk.NewInventory(nodes, nil, evengsdk.InventoryOptions{})
+	for name, id := range map[string]int{"R1": 1, "R1_2_2": 2, "R1_2": 3, "_4": 4} {
+		vars, ok := inventory.Hosts[name]
+		if !ok {
+			t.Fatalf("missing host %s in %v", name, inventory.Groups)
+		}
+		if vars["eve_id"] != id {
+			t.Fatalf("host %s is node %v, want %d", name, vars["eve_id"], id)
+		}
+	}
+	if len(inventory.Hosts) != len(nodes) {
+		t.Fatalf("expected %d hosts, got %d", len(nodes), len(inventory.Hosts))
+	}
+}
+
+func TestInventory_WriteINI(t *testing.T) {
+	inventory := evengsdk.NewInventory(inventoryNodes(), nil, evengsdk.InventoryOptions{GroupBy: evengsdk.GroupByType})
+	var buf bytes.Buffer
+	err := inventory.WriteINI(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.HasPrefix(out, "[qemu]\nR1 ") {
+		t.Fatalf("unexpected inventory:\n%s", out)
+	}
+	if !strings.Contains(out, "\n[vpcs]\nPC1 ") {
+		t.Fatalf("missing vpcs group in:\n%s", out)
+	}
+}
+
+func TestInventory_RunScript(t *testing.T) {
+	inventory := evengsdk.NewInventory(inventoryNodes(), nil, evengsdk.InventoryOptions{})
+	var buf bytes.Buffer
+	err := inventory.RunScript([]string{"--list"}, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var list map[string]json.RawMessage
+	err = json.Unmarshal(buf.Bytes(), &list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := list["_meta"]; !ok {
+		t.Fatal("missing _meta")
+	}
+	if _, ok := list["viosl2"]; !ok {
+		t.Fatal("missing viosl2 group")
+	}
+	buf.Reset()
+	err = inventory.RunScript([]string{"--host", "SW1"}, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var vars map[string]interface{}
+	err = json.Unmarshal(buf.Bytes(), &vars)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vars["eve_console_port"] != float64(32770) {
+		t.Fatalf("unexpected vars: %v", vars)
+	}
+	err = inventory.RunScript(nil, &buf)
+	if err == nil {
+		t.Fatal("Should have failed")
+	}
+}
+
+func TestLabService_GetInventory(t *testing.T) {
+	client, err := evengsdk.NewBasicAuthClient(os.Getenv("EVE_USER"), os.Getenv("EVE_PASSWORD"), "0", os.Getenv("EVE_HOST"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	time := time.Now()
+	err = client.Lab.CreateLab("/"+time.Format("15-04-05")+".unl", evengsdk.Lab{
+		Description: "Unit Test",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Lab.DeleteLab("/" + time.Format("15-04-05") + ".unl")
+	node := &evengsdk.Node{
+		Name:     "vpc",
+		Template: "vpcs",
+		Type:     "qemu",
+	}
+	err = client.Node.CreateNode("/"+time.Format("15-04-05")+".unl", node)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inventory, err := client.Lab.GetInventory("/"+time.Format("15-04-05")+".unl", evengsdk.InventoryOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := inventory.Hosts["vpc"]; !ok {
+		t.Fatal("Node is missing from the inventory")
+	}
+}
@@ -0,0 +1,183 @@
+package test
+
+import (
+	"encoding/json"
+	"github.com/CorentinPtrl/evengsdk"
+	"net/http"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func layoutTopology() *evengsdk.Topology {
+	topology := renderTopology()
+	topology.Nodes["3"] = evengsdk.Node{Id: 3, Name: "R3", Template: "vios", Left: 700, Top: 700}
+	topology.Links = append(topology.Links, evengsdk.TopologyLink{
+		Type: "ethernet", Source: "node2", SourceType: "node", SourceLabel: "Gi0/2",
+		Destination: "node3", DestinationType: "node", DestinationLabel: "Gi0/0", NetworkId: 5,
+	})
+	return topology
+}
+
+func TestComputeLayout(t *testing.T) {
+	for _, algorithm := range []evengsdk.LayoutAlgorithm{evengsdk.LayoutGrid, evengsdk.LayoutCircular, evengsdk.LayoutForce, evengsdk.LayoutHierarchical} {
+		opts := evengsdk.LayoutOptions{Algorithm: algorithm, Width: 800, Height: 600, PinnedNodes: []int{3}}
+		layout, err := evengsdk.ComputeLayout(layoutTopology(), opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(layout.Nodes) != 3 || len(layout.Networks) != 2 {
+			t.Fatalf("algorithm %d: unexpected layout %v", algorithm, layout)
+		}
+		if layout.Nodes[3] != (evengsdk.LayoutPosition{Left: 700, Top: 700}) {
+			t.Fatalf("algorithm %d: pinned node was moved to %v", algorithm, layout.Nodes[3])
+		}
+		seen := make(map[evengsdk.LayoutPosition]bool)
+		for id, pos := range layout.Nodes {
+			if id != 3 && (pos.Left < 50 || pos.Left > 750 || pos.Top < 50 || pos.Top > 550) {
+				t.Fatalf("algorithm %d: node %d is out of the canvas: %v", algorithm, id, pos)
+			}
+			seen[pos] = true
+		}
+		for _, pos := range layout.Networks {
+			seen[pos] = true
+		}
+		if len(seen) != 5 {
+			t.Fatalf("algorithm %d: vertices overlap: %v", algorithm, layout)
+		}
+		again, err := evengsdk.ComputeLayout(layoutTopology(), opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(layout, again) {
+			t.Fatalf("algorithm %d: layout is not deterministic", algorithm)
+		}
+	}
+}
+
+func TestComputeLayout_Hierarchical(t *testing.T) {
+	layout, err := evengsdk.ComputeLayout(layoutTopology(), evengsdk.LayoutOptions{Algorithm: evengsdk.LayoutHierarchical, Roots: []int{3}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !(layout.Nodes[3].Top < layout.Nodes[2].Top && layout.Nodes[2].Top < layout.Networks[1].Top && layout.Networks[1].Top < layout.Nodes[1].Top) {
+		t.Fatalf("unexpected tiers: %v", layout)
+	}
+}
+
+func TestComputeLayout_HiddenBridge(t *testing.T) {
+	topology := &evengsdk.Topology{
+		Nodes: map[string]evengsdk.Node{
+			"1": {Id: 1, Name: "R1", Template: "vios"},
+			"2": {Id: 2, Name: "R2", Template: "vios"},
+		},
+		Networks: map[string]evengsdk.Network{
+			"1": {Id: 1, Name: "R1_R2", Type: "bridge", Visibility: "0"},
+		},
+		Links: evengsdk.ParseTopologyLinks([]map[string]interface{}{
+			{"type": "ethernet", "source": "node1", "source_type": "node", "source_label": "Gi0/0", "destination": "network1", "destination_type": "network", "network_id": float64(1)},
+			{"type": "ethernet", "source": "node2", "source_type": "node", "source_label": "Gi0/0", "destination": "network1", "destination_type": "network", "network_id": float64(1)},
+		}),
+	}
+	layout, err := evengsdk.ComputeLayout(topology, evengsdk.LayoutOptions{Algorithm: evengsdk.LayoutHierarchical, Roots: []int{1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(layout.Networks) != 0 {
+		t.Fatalf("hidden bridge should not be placed: %v", layout)
+	}
+	if layout.Nodes[1].Top >= layout.Nodes[2].Top {
+		t.Fatalf("R2 should be below R1: %v", layout)
+	}
+}
+
+func TestComputeLayout_InvalidCanvas(t *testing.T) {
+	_, err := evengsdk.ComputeLayout(layoutTopology(), evengsdk.LayoutOptions{Width: 80, Height: 80})
+	if err == nil {
+		t.Fatal("Should have failed")
+	}
+}
+
+func TestLabService_ApplyLayout_Position(t *testing.T) {
+	var bodies []map[string]interface{}
+	client := apiServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && strings.HasSuffix(r.URL.Path, "/test.unl/nodes"):
+			apiResponse(w, map[string]interface{}{
+				"1": map[string]interface{}{"id": 1, "name": "R1", "type": "qemu", "template": "vios", "left": 0, "top": 0},
+				"2": map[string]interface{}{"id": 2, "name": "R2", "type": "qemu", "template": "vios", "left": 0, "top": 0},
+			})
+		case r.Method == "GET" && strings.HasSuffix(r.URL.Path, "/test.unl/networks"):
+			apiResponse(w, map[string]interface{}{})
+		case r.Method == "GET" && strings.HasSuffix(r.URL.Path, "/test.unl/topology"):
+			apiResponse(w, []interface{}{})
+		case r.Method == "PUT" && strings.Contains(r.URL.Path, "/test.unl/nodes/"):
+			var body map[string]interface{}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Error(err)
+			}
+			bodies = append(bodies, body)
+			apiResponse(w, nil)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	_, err := client.Lab.ApplyLayout("/test.unl", evengsdk.LayoutOptions{Algorithm: evengsdk.LayoutGrid})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bodies) != 2 {
+		t.Fatalf("expected 2 updates, got %v", bodies)
+	}
+	for _, body := range bodies {
+		if _, ok := body["left"]; !ok || len(body) != 2 {
+			t.Fatalf("only the position should be sent, got %v", body)
+		}
+		if _, ok := body["top"]; !ok {
+			t.Fatalf("only the position should be sent, got %v", body)
+		}
+	}
+}
+
+func TestLabService_ApplyLayout(t *testing.T) {
+	client, err := evengsdk.NewBasicAuthClient(os.Getenv("EVE_USER"), os.Getenv("EVE_PASSWORD"), "0", os.Getenv("EVE_HOST"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	curtime := time.Now()
+	err = client.Lab.CreateLab("/"+curtime.Format("15-04-05")+".unl", evengsdk.Lab{
+		Description: "Unit Test",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Lab.DeleteLab("/" + curtime.Format("15-04-05") + ".unl")
+	for i := 0; i < 4; i++ {
+		net := &evengsdk.Network{
+			Name:       "test_network_" + strconv.Itoa(i),
+			Type:       "bridge",
+			Visibility: "1",
+			Icon:       "01-Cloud-Default.svg",
+		}
+		err = client.Network.CreateNetwork("/"+curtime.Format("15-04-05")+".unl", net)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	layout, err := client.Lab.ApplyLayout("/"+curtime.Format("15-04-05")+".unl", evengsdk.LayoutOptions{Algorithm: evengsdk.LayoutGrid})
+	if err != nil {
+		t.Fatal(err)
+	}
+	networks, err := client.Network.GetNetworks("/" + curtime.Format("15-04-05") + ".unl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, net := range networks {
+		if layout.Networks[net.Id] != (evengsdk.LayoutPosition{Left: net.Left, Top: net.Top}) {
+			t.Fatal("Network was not moved")
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package test
+
+import (
+	"github.com/CorentinPtrl/evengsdk"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestParseEndpoint(t *testing.T) {
+	endpoint, err := evengsdk.ParseEndpoint("R1:Gi0/0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if endpoint.Node != "R1" || endpoint.Interface != "Gi0/0" || endpoint.String() != "R1:Gi0/0" {
+		t.Fatalf("unexpected endpoint %v", endpoint)
+	}
+	for _, invalid := range []string{"R1", ":Gi0/0", "R1:"} {
+		if _, err := evengsdk.ParseEndpoint(invalid); err == nil {
+			t.Fatalf("%q should be invalid", invalid)
+		}
+	}
+}
+
+func TestNodeLinks(t *testing.T) {
+	topology := renderTopology()
+	topology.Nodes["3"] = evengsdk.Node{Id: 3, Name: "R3", Template: "iol"}
+	topology.Networks["1"] = evengsdk.Network{Id: 1, Name: "Net1", Type: "bridge", Visibility: "0"}
+	topology.Links = append(topology.Links, evengsdk.ParseTopologyLinks([]map[string]interface{}{
+		{"type": "serial", "source": "node3", "source_type": "node", "source_label": "s1/0", "destination": "node1", "destination_type": "node", "destination_label": "s1/0"},
+	})...)
+	links := evengsdk.NodeLinks(topology)
+	want := []string{"R1:Gi0/0 - R2:Gi0/1", "R1:Gi0/2 - Mgmt", "R1:s1/0 - R3:s1/0"}
+	if len(links) != len(want) {
+		t.Fatalf("unexpected links %v", links)
+	}
+	for i, link := range links {
+		if link.String() != want[i] {
+			t.Fatalf("unexpected links %v", links)
+		}
+	}
+	if !links[0].Hidden || links[0].NetworkId != 1 || links[1].Hidden || links[2].Kind != evengsdk.InterfaceSerial {
+		t.Fatalf("unexpected links %+v", links)
+	}
+}
+
+func TestLinkService_Connect(t *testing.T) {
+	client, err := evengsdk.NewBasicAuthClient(os.Getenv("EVE_USER"), os.Getenv("EVE_PASSWORD"), "0", os.Getenv("EVE_HOST"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lab := "/" + time.Now().Format("15-04-05") + ".unl"
+	err = client.Lab.CreateLab(lab, evengsdk.Lab{
+		Description: "Unit Test",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Lab.DeleteLab(lab)
+	for _, name := range []string{"R1", "R2"} {
+		node, err := client.Node.NewNodeFromTemplate("vpcs", evengsdk.Node{Name: name})
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = client.Node.CreateNode(lab, node)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	link, err := client.Link.Connect(lab, "R1:eth0", "R2:eth0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !link.Hidden || link.NetworkId == 0 {
+		t.Fatalf("unexpected link %+v", link)
+	}
+	if _, err := client.Link.Connect(lab, "R1:eth0", "R2:eth0"); err == nil {
+		t.Fatal("Should have failed")
+	}
+	links, err := client.Link.GetLinks(lab)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(links) != 1 || links[0].String() != "R1:eth0 - R2:eth0" {
+		t.Fatalf("unexpected links %v", links)
+	}
+	err = client.Link.Disconnect(lab, "R1:eth0", "R2:eth0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	networks, err := client.Network.GetNetworks(lab)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(networks) != 0 {
+		t.Fatalf("unexpected networks %v", networks)
+	}
+}
@@ -0,0 +1,93 @@
+package test
+
+import (
+	"bytes"
+	"github.com/CorentinPtrl/evengsdk"
+	"net/netip"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNetworkType(t *testing.T) {
+	if evengsdk.NetworkPnet(3) != evengsdk.NetworkPnet3 || !evengsdk.NetworkPnet0.Cloud() || evengsdk.NetworkBridge.Cloud() {
+		t.Fatal("unexpected network types")
+	}
+}
+
+func TestPlanManagement(t *testing.T) {
+	nodes := map[string]evengsdk.Node{
+		"1": {Id: 1, Name: "R1"},
+		"2": {Id: 2, Name: "R2"},
+	}
+	attachments := []evengsdk.Attachment{
+		{NodeId: 2, Interface: "Gi0/0", NetworkId: 1},
+		{NodeId: 1, Interface: "Gi0/0", NetworkId: 1},
+	}
+	plan, err := evengsdk.PlanManagement(nodes, attachments, evengsdk.ManagementOptions{Prefix: netip.MustParsePrefix("192.168.100.0/24")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.Gateway.String() != "192.168.100.1" || len(plan.Hosts) != 2 || plan.Hosts[0].Node != "R1" || plan.Hosts[0].Address.String() != "192.168.100.11/24" {
+		t.Fatalf("unexpected plan %+v", plan)
+	}
+	vars := plan.TemplateVars()
+	if vars["R2"]["mgmt_ip"] != "192.168.100.12" || vars["R2"]["mgmt_netmask"] != "255.255.255.0" || vars["R2"]["mgmt_gateway"] != "192.168.100.1" {
+		t.Fatalf("unexpected vars %v", vars)
+	}
+	var buf bytes.Buffer
+	if err := plan.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "1,R1,Gi0/0,192.168.100.11/24,192.168.100.1\n") {
+		t.Fatalf("unexpected CSV %q", buf.String())
+	}
+	_, err = evengsdk.PlanManagement(nodes, attachments, evengsdk.ManagementOptions{Prefix: netip.MustParsePrefix("192.168.100.0/29"), HostOffset: 5})
+	if err == nil {
+		t.Fatal("Should have failed")
+	}
+	_, err = evengsdk.PlanManagement(nodes, attachments, evengsdk.ManagementOptions{Prefix: netip.MustParsePrefix("192.168.100.0/24"), Gateway: netip.MustParseAddr("192.168.100.12")})
+	if err == nil {
+		t.Fatal("Should have failed")
+	}
+}
+
+func TestNetworkService_AttachManagement(t *testing.T) {
+	client, err := evengsdk.NewBasicAuthClient(os.Getenv("EVE_USER"), os.Getenv("EVE_PASSWORD"), "0", os.Getenv("EVE_HOST"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lab := "/" + time.Now().Format("15-04-05") + ".unl"
+	err = client.Lab.CreateLab(lab, evengsdk.Lab{
+		Description: "Unit Test",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Lab.DeleteLab(lab)
+	for _, name := range []string{"PC1", "PC2"} {
+		node, err := client.Node.NewNodeFromTemplate("vpcs", evengsdk.Node{Name: name})
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = client.Node.CreateNode(lab, node)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	result, err := client.Network.AttachManagement(lab, evengsdk.ManagementOptions{Prefix: netip.MustParsePrefix("192.168.100.0/24")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Network.Type != evengsdk.NetworkPnet0 || len(result.Attachments) != 2 || len(result.Plan.Hosts) != 2 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	again, err := client.Network.AttachManagement(lab, evengsdk.ManagementOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Network.Id != result.Network.Id {
+		t.Fatal("The management network should have been reused")
+	}
+}
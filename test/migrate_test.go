@@ -0,0 +1,225 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/CorentinPtrl/evengsdk"
+	"image"
+	"image/png"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMigrationReport_Err(t *testing.T) {
+	report := &evengsdk.MigrationReport{}
+	if report.Err() != nil {
+		t.Fatal("Empty report should not fail")
+	}
+	report.Issues = append(report.Issues, evengsdk.MigrationIssue{Kind: "image", Item: "R1", Err: errors.New("Image \"vios-15\" is not installed for template vios")})
+	if err := report.Err(); err == nil || !strings.HasPrefix(err.Error(), "image R1: Image") {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestLabService_GetPictureData(t *testing.T) {
+	var data bytes.Buffer
+	if err := png.Encode(&data, image.NewRGBA(image.Rect(0, 0, 16, 16))); err != nil {
+		t.Fatal(err)
+	}
+	client := apiServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/test.unl/pictures/1/data") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(data.Bytes())
+	})
+	got, err := client.Lab.GetPictureData("/test.unl", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data.Bytes()) {
+		t.Fatalf("unexpected picture data %q", got)
+	}
+}
+
+func TestMigrateLab_Rollback(t *testing.T) {
+	src := apiServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/labs/test.unl":
+			apiResponse(w, map[string]interface{}{"name": "test", "author": "admin", "description": "Rollback", "version": "1"})
+		case strings.HasSuffix(r.URL.Path, "/test.unl/nodes"):
+			apiResponse(w, map[string]interface{}{"1": map[string]interface{}{"id": 1, "name": "R1", "type": "qemu", "template": "vios", "status": 0}})
+		case strings.HasSuffix(r.URL.Path, "/test.unl/nodes/1"):
+			// Only GetNode returns the qemu settings.
+			apiResponse(w, map[string]interface{}{"name": "R1", "type": "qemu", "template": "vios", "status": 0, "qemu_options": "-nographic"})
+		case strings.HasSuffix(r.URL.Path, "/test.unl/networks"):
+			apiResponse(w, map[string]interface{}{"1": map[string]interface{}{"id": 1, "name": "Net1", "type": "bridge", "visibility": "1"}})
+		default:
+			t.Errorf("unexpected source request %s %s", r.Method, r.URL.Path)
+		}
+	})
+	var failNetworks, deleted bool
+	var created map[string]interface{}
+	dst := apiServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && strings.HasSuffix(r.URL.Path, "/migrated.unl/networks") && !failNetworks:
+			apiResponse(w, map[string]interface{}{"id": 1})
+		case r.Method == "POST" && strings.HasSuffix(r.URL.Path, "/migrated.unl/nodes"):
+			json.NewDecoder(r.Body).Decode(&created)
+			fallthrough
+		case r.Method == "POST" && strings.HasSuffix(r.URL.Path, "/migrated.unl/networks"),
+			r.Method == "GET" && r.URL.Path == "/api/labs/migrated.unl":
+			// Dropping the connection fails the request without retrying it.
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err == nil {
+				conn.Close()
+			}
+		case r.URL.Path == "/api/list/templates/vios":
+			apiResponse(w, json.RawMessage(viosTemplate))
+		case r.URL.Path == "/api/list/networks":
+			apiResponse(w, map[string]interface{}{"bridge": "bridge"})
+		case r.Method == "POST" && r.URL.Path == "/api/labs":
+			apiResponse(w, nil)
+		case r.Method == "DELETE" && r.URL.Path == "/api/labs/migrated.unl":
+			deleted = true
+			apiResponse(w, nil)
+		default:
+			t.Errorf("unexpected destination request %s %s", r.Method, r.URL.Path)
+		}
+	})
+
+	// A lab without its network is deleted before any node is created.
+	failNetworks = true
+	report, err := evengsdk.MigrateLab(src, "/test.unl", dst, "/migrated.unl")
+	if err == nil || len(report.Issues) != 1 || report.Issues[0].Kind != "network" {
+		t.Fatalf("unexpected report %+v: %v", report, err)
+	}
+	if !deleted || created != nil {
+		t.Fatalf("unexpected destination, deleted %v, created %v", deleted, created)
+	}
+
+	failNetworks, deleted = false, false
+	report, err = evengsdk.MigrateLab(src, "/test.unl", dst, "/migrated.unl")
+	if err == nil || len(report.Issues) != 1 || report.Issues[0].Kind != "node" {
+		t.Fatalf("unexpected report %+v: %v", report, err)
+	}
+	if !deleted {
+		t.Fatal("Destination lab not deleted")
+	}
+	if created["qemu_options"] != "-nographic" {
+		t.Fatalf("unexpected node %v", created)
+	}
+}
+
+func TestMigrateLab(t *testing.T) {
+	client, err := evengsdk.NewBasicAuthClient(os.Getenv("EVE_USER"), os.Getenv("EVE_PASSWORD"), "0", os.Getenv("EVE_HOST"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Migrating to another path of the same server exercises the whole copy.
+	src := "/" + time.Now().Format("15-04-05") + ".unl"
+	dst := "/" + time.Now().Format("15-04-05") + "-migrated.unl"
+	err = client.Lab.CreateLab(src, evengsdk.Lab{Name: src[1 : len(src)-4], Description: "Migration"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Lab.DeleteLab(src)
+	ids := make(map[string]int)
+	for _, name := range []string{"PC1", "PC2"} {
+		node := &evengsdk.Node{Name: name, Template: "vpcs", Type: evengsdk.NodeTypeVpcs, Console: evengsdk.ConsoleTelnet}
+		if err := client.Node.CreateNode(src, node); err != nil {
+			t.Fatal(err)
+		}
+		ids[name] = node.Id
+		if err := client.Node.UpdateNodeConfig(src, node.Id, "ip 10.0.0."+name[2:]+"/24"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := client.Link.Connect(src, "PC1:eth0", "PC2:eth0"); err != nil {
+		t.Fatal(err)
+	}
+	// GetNodes does not return the qemu settings, the migration has to fetch them node by node.
+	images, err := client.Node.GetImages()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var qemu *evengsdk.Node
+	for _, template := range images {
+		info, err := client.Node.GetTemplate(template.Template)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Type == evengsdk.NodeTypeQemu && len(template.Images) > 0 {
+			qemu = &evengsdk.Node{Name: "R1", Template: template.Template, Type: evengsdk.NodeTypeQemu, Image: template.Images[0]}
+			qemu.QemuOptions = "-machine type=pc -nographic -rtc base=utc"
+			break
+		}
+	}
+	if qemu == nil {
+		t.Fatal("No qemu image installed")
+	}
+	if err := client.Node.CreateNode(src, qemu); err != nil {
+		t.Fatal(err)
+	}
+	ids["R1"] = qemu.Id
+	if err := client.Lab.CreateTextObject(src, &evengsdk.TextObject{Name: "txt 1", Type: "text", Data: "PGRpdj5MYWI8L2Rpdj4="}); err != nil {
+		t.Fatal(err)
+	}
+	var data bytes.Buffer
+	if err := png.Encode(&data, image.NewRGBA(image.Rect(0, 0, 16, 16))); err != nil {
+		t.Fatal(err)
+	}
+	picture := &evengsdk.Picture{
+		Name: "diagram",
+		Type: "image/png",
+		Map:  "<area shape='rect' alt='PC1' coords='0,0,8,8' href='telnet://{{IP}}:{{NODE" + strconv.Itoa(ids["PC1"]) + "}}'>",
+	}
+	if err := client.Lab.CreatePicture(src, picture, data.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := evengsdk.MigrateLab(client, src, client, dst, evengsdk.MigrateOptions{})
+	defer client.Lab.DeleteLab(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Nodes) != 3 || len(report.Networks) != 1 || report.Configs != 2 || report.TextObjects != 1 || report.Pictures != 1 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	migrated, err := client.Node.GetNode(dst, report.Nodes[ids["R1"]])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if migrated.QemuOptions != qemu.QemuOptions || migrated.Image != qemu.Image {
+		t.Fatalf("unexpected qemu node %+v", migrated)
+	}
+	pictures, err := client.Lab.GetPictures(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, migrated := range pictures {
+		migrated, err := client.Lab.GetPicture(dst, migrated.Id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(migrated.Map, "{{NODE"+strconv.Itoa(report.Nodes[ids["PC1"]])+"}}") {
+			t.Fatalf("unexpected image map %q", migrated.Map)
+		}
+	}
+	links, err := client.Link.GetLinks(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(links) != 1 || links[0].String() != "PC1:eth0 - PC2:eth0" || !links[0].Hidden {
+		t.Fatalf("unexpected links %v", links)
+	}
+	if _, err := evengsdk.MigrateLab(client, src, client, dst); err == nil {
+		t.Fatal("Should have failed on the existing destination")
+	}
+}
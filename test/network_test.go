@@ -325,3 +325,26 @@ func TestNetworkService_GetNetworksList(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestNetworkService_GetNetworkTypes(t *testing.T) {
+	client, err := evengsdk.NewBasicAuthClient(os.Getenv("EVE_USER"), os.Getenv("EVE_PASSWORD"), "0", os.Getenv("EVE_HOST"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	types, err := client.Network.GetNetworkTypes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i < len(types); i++ {
+		if types[i-1].Type >= types[i].Type {
+			t.Fatalf("unsorted network types %v", types)
+		}
+	}
+	if err := client.Network.ValidateType(evengsdk.NetworkBridge); err != nil {
+		t.Fatal(err)
+	}
+	err = client.Network.CreateNetwork("/missing.unl", &evengsdk.Network{Name: "Invalid", Type: "vlan"})
+	if err == nil {
+		t.Fatal("Should have failed")
+	}
+}
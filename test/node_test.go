@@ -1,8 +1,11 @@
 package test
 
 import (
+	"encoding/json"
 	"github.com/CorentinPtrl/evengsdk"
+	"net/http"
 	"os"
+	"strings"
 	"testing"
 	"time"
 )
@@ -414,3 +417,145 @@ func TestNodeService_GetTemplate(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestNode_JSON(t *testing.T) {
+	data := `{"id":1,"name":"R1","type":"qemu","status":2,"console":"telnet","firstmac":"50:00:00:01:00:00","qemu_options":"-machine type=pc,accel=kvm","qemu_version":"4.1.0","qemu_arch":"x86_64","qemu_nic":"e1000","cpulimit":1,"sat":30,"username":"admin","slot1":"PA-FE-TX","nvram":1024}`
+	var node evengsdk.Node
+	if err := json.Unmarshal([]byte(data), &node); err != nil {
+		t.Fatal(err)
+	}
+	if node.QemuOptions != "-machine type=pc,accel=kvm" || node.QemuNic != "e1000" || node.CpuLimit != 1 || node.Sat != 30 || node.Nvram != 1024 {
+		t.Fatalf("unexpected node %+v", node)
+	}
+	if string(node.Extra["slot1"]) != `"PA-FE-TX"` || len(node.Extra) != 1 {
+		t.Fatalf("unexpected extra fields %v", node.Extra)
+	}
+	node.Name = "R2"
+	out, err := json.Marshal(&node)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got, want map[string]interface{}
+	if err := json.Unmarshal(out, &got); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(data), &want); err != nil {
+		t.Fatal(err)
+	}
+	want["name"] = "R2"
+	for key, value := range want {
+		if got[key] != value {
+			t.Fatalf("%s: got %v, want %v", key, got[key], value)
+		}
+	}
+	if _, ok := got["environment"]; ok {
+		t.Fatal("Unset settings should be omitted")
+	}
+}
+
+func TestNodeService_UpdateNode_ZeroSettings(t *testing.T) {
+	var body map[string]interface{}
+	client := apiServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PUT" || !strings.HasSuffix(r.URL.Path, "/test.unl/nodes/1") {
+			http.NotFound(w, r)
+			return
+		}
+		body = nil
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		apiResponse(w, nil)
+	})
+	// A node fetched with its settings can reset them.
+	var node evengsdk.Node
+	if err := json.Unmarshal([]byte(`{"id":1,"name":"R1","type":"qemu","cpulimit":1,"qemu_nic":"virtio-net-pci","qemu_options":"-nographic"}`), &node); err != nil {
+		t.Fatal(err)
+	}
+	node.CpuLimit = 0
+	node.QemuNic = "e1000"
+	err := client.Node.UpdateNode("/test.unl", &node)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, ok := body["cpulimit"]; !ok || value != 0.0 {
+		t.Fatalf("cpulimit should be sent as 0, got %v", body)
+	}
+	if body["qemu_nic"] != "e1000" || body["qemu_options"] != "-nographic" {
+		t.Fatalf("unexpected settings in %v", body)
+	}
+	if _, ok := body["serial"]; ok {
+		t.Fatal("Settings the node was not fetched with should be omitted")
+	}
+
+	// A node built by hand, or listed by GetNodes, leaves the settings it does not set untouched.
+	err = client.Node.UpdateNode("/test.unl", &evengsdk.Node{Id: 1, Name: "R1", Type: evengsdk.NodeTypeQemu})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range []string{"cpulimit", "qemu_options", "firstmac", "username", "password", "sat"} {
+		if _, ok := body[field]; ok {
+			t.Fatalf("%s should not be sent in %v", field, body)
+		}
+	}
+}
+
+func TestInterfaces_Find(t *testing.T) {
+	data := `{"ethernet":{"0":{"name":"e0/0","network_id":1},"16":{"name":"e0/1","network_id":0}},"serial":{"32":{"name":"s1/0","remote_id":"2","remote_if":"48"},"48":{"name":"s1/1","remote_id":"","remote_if":""}}}`
+	var interfaces evengsdk.Interfaces
+	if err := json.Unmarshal([]byte(data), &interfaces); err != nil {
+		t.Fatal(err)
+	}
+	ref, intf, ok := interfaces.Find("s1/0")
+	if !ok || ref.Kind != evengsdk.InterfaceSerial || ref.Index != 32 || intf.RemoteId != 2 || intf.RemoteIf != 48 {
+		t.Fatalf("unexpected interface %v %v", ref, intf)
+	}
+	ref, intf, ok = interfaces.Find("e0/0")
+	if !ok || ref.Kind != evengsdk.InterfaceEthernet || ref.Index != 0 || intf.NetworkId != 1 {
+		t.Fatalf("unexpected interface %v %v", ref, intf)
+	}
+	if _, _, ok := interfaces.Find("s9/9"); ok {
+		t.Fatal("Should not have been found")
+	}
+}
+
+func TestNodeService_ConnectSerial(t *testing.T) {
+	client, err := evengsdk.NewBasicAuthClient(os.Getenv("EVE_USER"), os.Getenv("EVE_PASSWORD"), "0", os.Getenv("EVE_HOST"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lab := "/" + time.Now().Format("15-04-05") + ".unl"
+	err = client.Lab.CreateLab(lab, evengsdk.Lab{
+		Description: "Unit Test",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Lab.DeleteLab(lab)
+	var ids []int
+	for _, name := range []string{"R1", "R2"} {
+		node, err := client.Node.NewNodeFromTemplate("iol", evengsdk.Node{Name: name, Ethernet: 1, IolSettings: evengsdk.IolSettings{Serial: 1}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = client.Node.CreateNode(lab, node)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, node.Id)
+	}
+	err = client.Node.ConnectSerialByName(lab, ids[0], "s1/0", ids[1], "s1/0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, intf, err := client.Node.GetNodeInterfaceRef(lab, ids[1], "s1/0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref.Kind != evengsdk.InterfaceSerial || intf.RemoteId != ids[0] {
+		t.Fatalf("unexpected interface %v %v", ref, intf)
+	}
+	err = client.Node.UpdateNodeInterfaceName(lab, ids[0], "s1/0", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,70 @@
+package test
+
+import (
+	"encoding/json"
+	"github.com/CorentinPtrl/evengsdk"
+	"testing"
+)
+
+func TestNodeStatus_JSON(t *testing.T) {
+	var node evengsdk.Node
+	for data, want := range map[string]evengsdk.NodeStatus{
+		`{"status":2}`:         evengsdk.NodeStatusRunning,
+		`{"status":"0"}`:       evengsdk.NodeStatusStopped,
+		`{"status":"running"}`: evengsdk.NodeStatusRunning,
+		`{"status":3}`:         evengsdk.NodeStatusBusy,
+	} {
+		if err := json.Unmarshal([]byte(data), &node); err != nil {
+			t.Fatal(err)
+		}
+		if node.Status != want {
+			t.Fatalf("%s: unexpected status %v", data, node.Status)
+		}
+	}
+	if err := json.Unmarshal([]byte(`{"status":"exploded"}`), &node); err == nil {
+		t.Fatal("Should have failed")
+	}
+	data, err := json.Marshal(evengsdk.Node{Status: evengsdk.NodeStatusRunning, Type: evengsdk.NodeTypeIol, Console: evengsdk.ConsoleTelnet})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatal(err)
+	}
+	if raw["status"] != float64(2) || raw["type"] != "iol" || raw["console"] != "telnet" {
+		t.Fatalf("unexpected JSON %s", data)
+	}
+	if evengsdk.NodeStatusRunning.String() != "running" || evengsdk.NodeStatus(7).String() != "NodeStatus(7)" {
+		t.Fatal("unexpected status names")
+	}
+	if !evengsdk.NodeStatusBusy.Running() || evengsdk.NodeStatusStarting.Running() {
+		t.Fatal("unexpected running statuses")
+	}
+}
+
+func TestNode_Validate(t *testing.T) {
+	valid := []evengsdk.Node{
+		{Type: evengsdk.NodeTypeQemu},
+		{Type: evengsdk.NodeTypeQemu, Console: evengsdk.ConsoleVnc},
+		{Type: evengsdk.NodeTypeDocker, Console: evengsdk.ConsoleHttps},
+		{Type: evengsdk.NodeTypeVpcs, Console: evengsdk.ConsoleTelnet},
+	}
+	for _, node := range valid {
+		if err := node.Validate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	invalid := []evengsdk.Node{
+		{},
+		{Type: "kvm"},
+		{Type: evengsdk.NodeTypeQemu, Console: "ssh"},
+		{Type: evengsdk.NodeTypeIol, Console: evengsdk.ConsoleVnc},
+		{Type: evengsdk.NodeTypeVpcs, Console: evengsdk.ConsoleRdp},
+	}
+	for _, node := range invalid {
+		if err := node.Validate(); err == nil {
+			t.Fatalf("%v should be invalid", node)
+		}
+	}
+}
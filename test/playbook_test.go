@@ -0,0 +1,190 @@
+package test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"github.com/CorentinPtrl/evengsdk"
+	"github.com/CorentinPtrl/evengsdk/console"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// iosConsole is a minimal stand-in for the console of a freshly booted IOS router.
+// Every line received is appended to commands.
+func iosConsole(commands chan<- string) func(net.Conn) {
+	return func(conn net.Conn) {
+		conn.Write([]byte("\r\n--- System Configuration Dialog ---\r\n\r\nWould you like to enter the initial configuration dialog? [yes/no]: "))
+		reader := bufio.NewReader(conn)
+		prompt := "Router>"
+		state := "dialog"
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			commands <- line
+			switch {
+			case state == "dialog":
+				state = "return"
+				conn.Write([]byte(line + "\r\n\r\nPress RETURN to get started!\r\n"))
+				continue
+			case state == "return":
+				state = "exec"
+			case state == "enable":
+				state = "exec"
+				if line == "secret" {
+					prompt = "Router#"
+				}
+				conn.Write([]byte("\r\n" + prompt))
+				continue
+			case line == "enable":
+				state = "enable"
+				conn.Write([]byte(line + "\r\nPassword: "))
+				continue
+			case line == "configure terminal":
+				prompt = "Router(config)#"
+			case strings.HasPrefix(line, "interface"):
+				prompt = "Router(config-if)#"
+			case line == "end":
+				prompt = "Router#"
+			case line == "write memory":
+				conn.Write([]byte(line + "\r\nBuilding configuration...\r\n[OK]\r\n" + prompt))
+				continue
+			}
+			conn.Write([]byte(line + "\r\n" + prompt))
+		}
+	}
+}
+
+func TestPlaybook_IOSProfile(t *testing.T) {
+	commands := make(chan string, 100)
+	addr := consoleServer(t, iosConsole(commands))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	conn, err := console.Dial(ctx, addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	playbook, err := console.Profile("vios", console.ProfileOptions{
+		EnableSecret: "secret",
+		Config:       "hostname Router\ninterface Gi0/0\n no shutdown",
+		Save:         true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var transcript strings.Builder
+	err = playbook.Run(ctx, conn, &transcript)
+	if err != nil {
+		t.Fatal(err)
+	}
+	close(commands)
+	var sent []string
+	for command := range commands {
+		sent = append(sent, command)
+	}
+	want := []string{"no", "", "enable", "secret", "terminal length 0", "configure terminal", "hostname Router", "interface Gi0/0", " no shutdown", "end", "write memory"}
+	if strings.Join(sent, "|") != strings.Join(want, "|") {
+		t.Fatalf("unexpected commands %q", sent)
+	}
+	if !strings.Contains(transcript.String(), "[OK]") {
+		t.Fatalf("unexpected transcript %q", transcript.String())
+	}
+}
+
+func TestPlaybook_StepFailure(t *testing.T) {
+	addr := consoleServer(t, routerConsole("Router>"))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	conn, err := console.Dial(ctx, addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	playbook := &console.Playbook{
+		Name:    "test",
+		Timeout: 100 * time.Millisecond,
+		Retries: 1,
+		Steps: []console.Step{
+			{Name: "banner", Expect: regexp.MustCompile(`Router>$`), NoSend: true},
+			{Name: "optional", Expect: regexp.MustCompile(`Username:`), Optional: true, Retries: -1},
+			{Name: "missing", Expect: regexp.MustCompile(`Password:`)},
+		},
+	}
+	err = playbook.Run(ctx, conn, nil)
+	var stepErr *console.StepError
+	if !errors.As(err, &stepErr) {
+		t.Fatalf("expected a step error, got %v", err)
+	}
+	if stepErr.Name != "missing" || stepErr.Step != 2 {
+		t.Fatalf("unexpected step error %v", stepErr)
+	}
+}
+
+func TestConsole_Profiles(t *testing.T) {
+	for _, name := range []string{"iol", "linux", "vios", "viosl2", "vpcs"} {
+		if _, err := console.Profile(name, console.ProfileOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(console.Profiles()) != 5 {
+		t.Fatalf("unexpected profiles %v", console.Profiles())
+	}
+	if _, err := console.Profile("unknown", console.ProfileOptions{}); err == nil {
+		t.Fatal("Should have failed")
+	}
+}
+
+func TestConsole_RunLab(t *testing.T) {
+	client, err := evengsdk.NewBasicAuthClient(os.Getenv("EVE_USER"), os.Getenv("EVE_PASSWORD"), "0", os.Getenv("EVE_HOST"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	time := time.Now()
+	err = client.Lab.CreateLab("/"+time.Format("15-04-05")+".unl", evengsdk.Lab{
+		Description: "Unit Test",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Lab.DeleteLab("/" + time.Format("15-04-05") + ".unl")
+	node := &evengsdk.Node{
+		Name:     "vpc",
+		Template: "vpcs",
+		Type:     "vpcs",
+	}
+	err = client.Node.CreateNode("/"+time.Format("15-04-05")+".unl", node)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = client.Node.StartNodes("/" + time.Format("15-04-05") + ".unl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Node.StopNodes("/" + time.Format("15-04-05") + ".unl")
+	dir := t.TempDir()
+	results, err := console.RunLab(context.Background(), client, "/"+time.Format("15-04-05")+".unl", console.LabOptions{
+		Playbook: console.NodeProfile(func(node evengsdk.Node) console.ProfileOptions {
+			return console.ProfileOptions{Config: "ip 10.0.0.1/24"}
+		}),
+		TranscriptDir: dir,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Err != nil {
+		t.Fatal("Playbook failed")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "vpc_"+strconv.Itoa(results[0].NodeId)+".log")); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,112 @@
+package test
+
+import (
+	"github.com/CorentinPtrl/evengsdk"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestPool_Placement(t *testing.T) {
+	members := []*evengsdk.PoolMember{
+		{Name: "eve1", Client: &evengsdk.Client{}, Labels: map[string]string{"site": "paris"}},
+		{Name: "eve2", Client: &evengsdk.Client{}, Labels: map[string]string{"site": "lyon", "gpu": "yes"}},
+		{Name: "eve3", Client: &evengsdk.Client{}, Labels: map[string]string{"site": "lyon"}},
+	}
+	if _, err := evengsdk.NewPool(nil, members[0], members[0]); err == nil {
+		t.Fatal("Should have failed on the duplicate server")
+	}
+	pool, err := evengsdk.NewPool(&evengsdk.RoundRobin{}, members...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	robin := &evengsdk.RoundRobin{}
+	for _, want := range []string{"eve1", "eve2", "eve3", "eve1"} {
+		member, err := robin.Place(pool.Members(), "/lab.unl")
+		if err != nil || member.Name != want {
+			t.Fatalf("got %v %v, want %s", member, err, want)
+		}
+	}
+	affinity := evengsdk.LabelAffinity{Labels: map[string]string{"site": "lyon"}, Fallback: &evengsdk.RoundRobin{}}
+	for _, want := range []string{"eve2", "eve3"} {
+		member, err := affinity.Place(pool.Members(), "/lab.unl")
+		if err != nil || member.Name != want {
+			t.Fatalf("got %v %v, want %s", member, err, want)
+		}
+	}
+	affinity.Labels = map[string]string{"site": "paris", "gpu": "yes"}
+	if _, err := affinity.Place(pool.Members(), "/lab.unl"); err == nil {
+		t.Fatal("Should have failed without a matching server")
+	}
+
+	if err := pool.Assign("/a/lab.unl", "eve2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := pool.Assign("/b/lab.unl", "eve4"); err == nil {
+		t.Fatal("Should have failed on the unknown server")
+	}
+	client, err := pool.Client("/a/lab.unl")
+	if err != nil || client != members[1].Client {
+		t.Fatalf("unexpected client %v, %v", client, err)
+	}
+	if assignments := pool.Assignments(); len(assignments) != 1 || assignments["/a/lab.unl"] != "eve2" {
+		t.Fatalf("unexpected assignments %v", assignments)
+	}
+}
+
+func TestLeastLoaded_Place(t *testing.T) {
+	usage := func(mem float64) map[string]interface{} {
+		return map[string]interface{}{"version": "6.2.0-4", "cpu": 10, "mem": mem, "swap": 0, "disk": 20}
+	}
+	notFound := func(w http.ResponseWriter, r *http.Request) { http.NotFound(w, r) }
+	big := &evengsdk.PoolMember{Name: "big", Client: statusServer(t, usage(75), notFound), Capacity: evengsdk.HostCapacity{Ram: 65536, Cpu: 32}}
+	small := &evengsdk.PoolMember{Name: "small", Client: statusServer(t, usage(50), notFound)}
+
+	// Without the capacity of every server, memory usage is compared.
+	member, err := evengsdk.LeastLoaded{}.Place([]*evengsdk.PoolMember{big, small}, "/lab.unl")
+	if err != nil || member != small {
+		t.Fatalf("got %v %v, want small", member, err)
+	}
+	small.Capacity = evengsdk.HostCapacity{Ram: 8192, Cpu: 4}
+	member, err = evengsdk.LeastLoaded{}.Place([]*evengsdk.PoolMember{big, small}, "/lab.unl")
+	if err != nil || member != big {
+		t.Fatalf("got %v %v, want big", member, err)
+	}
+}
+
+func TestPool_CreateLab(t *testing.T) {
+	client, err := evengsdk.NewBasicAuthClient(os.Getenv("EVE_USER"), os.Getenv("EVE_PASSWORD"), "0", os.Getenv("EVE_HOST"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool, err := evengsdk.NewPool(nil, &evengsdk.PoolMember{Name: "eve", Client: client})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lab := "/" + time.Now().Format("15-04-05") + ".unl"
+	member, err := pool.CreateLab(lab, evengsdk.Lab{Name: lab[1 : len(lab)-4], Description: "Pool"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if member.Name != "eve" {
+		t.Fatalf("unexpected server %s", member.Name)
+	}
+	if err := pool.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	nodes, err := pool.Node(lab)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := nodes.CreateNode(lab, &evengsdk.Node{Name: "PC1", Template: "vpcs", Type: evengsdk.NodeTypeVpcs, Console: evengsdk.ConsoleTelnet}); err != nil {
+		t.Fatal(err)
+	}
+	if err := pool.DeleteLab(lab); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pool.Locate(lab); err == nil {
+		t.Fatal("Lab should have been deleted")
+	}
+}
@@ -0,0 +1,121 @@
+package test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"github.com/CorentinPtrl/evengsdk"
+	"github.com/CorentinPtrl/evengsdk/console"
+	"net"
+	"os"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestTCPReady(t *testing.T) {
+	addr := consoleServer(t, func(conn net.Conn) {})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	node := &evengsdk.Node{Name: "R1", Url: "telnet://" + addr}
+	err := evengsdk.TCPReady{}.WaitReady(ctx, nil, "", node)
+	if err != nil {
+		t.Fatal(err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed := listener.Addr().String()
+	listener.Close()
+	ctx, cancel = context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	err = evengsdk.TCPReady{Address: closed, Interval: 50 * time.Millisecond}.WaitReady(ctx, nil, "", node)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected a deadline error, got %v", err)
+	}
+}
+
+func TestPromptReady(t *testing.T) {
+	// The console only shows the prompt after it has been woken up twice.
+	addr := consoleServer(t, func(conn net.Conn) {
+		reader := bufio.NewReader(conn)
+		for i := 0; ; i++ {
+			if _, err := reader.ReadString('\n'); err != nil {
+				return
+			}
+			if i == 1 {
+				conn.Write([]byte("\r\nRouter>"))
+			}
+		}
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	node := &evengsdk.Node{Name: "R1", Console: "telnet", Url: "telnet://" + addr}
+	strategy := console.PromptReady{Pattern: regexp.MustCompile(`Router>$`), Interval: 100 * time.Millisecond}
+	err := strategy.WaitReady(ctx, nil, "", node)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadyByTemplate(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	strategy := evengsdk.ReadyByTemplate{
+		Strategies: map[string]evengsdk.ReadyStrategy{"vios": evengsdk.TCPReady{Address: "127.0.0.1:1"}},
+	}
+	err := strategy.WaitReady(ctx, nil, "", &evengsdk.Node{Template: "vpcs"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = strategy.WaitReady(ctx, nil, "", &evengsdk.Node{Template: "vios"})
+	if err == nil {
+		t.Fatal("Should have failed")
+	}
+}
+
+func TestNodeService_WaitAllReady(t *testing.T) {
+	client, err := evengsdk.NewBasicAuthClient(os.Getenv("EVE_USER"), os.Getenv("EVE_PASSWORD"), "0", os.Getenv("EVE_HOST"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	curtime := time.Now()
+	err = client.Lab.CreateLab("/"+curtime.Format("15-04-05")+".unl", evengsdk.Lab{
+		Description: "Unit Test",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Lab.DeleteLab("/" + curtime.Format("15-04-05") + ".unl")
+	node := &evengsdk.Node{
+		Name:     "vpc",
+		Template: "vpcs",
+		Type:     "vpcs",
+	}
+	err = client.Node.CreateNode("/"+curtime.Format("15-04-05")+".unl", node)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = client.Node.StartNodes("/" + curtime.Format("15-04-05") + ".unl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Node.StopNodes("/" + curtime.Format("15-04-05") + ".unl")
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	err = client.Node.WaitReady(ctx, "/"+curtime.Format("15-04-05")+".unl", node.Id, evengsdk.StatusReady{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := client.Node.WaitAllReady(ctx, "/"+curtime.Format("15-04-05")+".unl", evengsdk.ReadySequence{
+		evengsdk.StatusReady{},
+		console.PromptReady{Pattern: regexp.MustCompile(`VPCS>`)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Err != nil {
+		t.Fatal("Node is not ready")
+	}
+}
@@ -0,0 +1,150 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"github.com/CorentinPtrl/evengsdk"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReaperOptions_TTLFor(t *testing.T) {
+	opts := evengsdk.ReaperOptions{
+		TTL:       4 * time.Hour,
+		FolderTTL: map[string]time.Duration{"/students": 2 * time.Hour, "/students/thesis/": 48 * time.Hour, "/teachers": 0},
+		Allow:     []string{"/demo.unl", "/shared"},
+	}
+	tests := []struct {
+		lab string
+		ttl time.Duration
+		ok  bool
+	}{
+		{"/lab.unl", 4 * time.Hour, true},
+		{"/students/alice/lab.unl", 2 * time.Hour, true},
+		{"/students/thesis/lab.unl", 48 * time.Hour, true},
+		{"/studentsX/lab.unl", 4 * time.Hour, true},
+		{"/teachers/lab.unl", 0, false},
+		{"/demo.unl", 0, false},
+		{"/shared/a/b.unl", 0, false},
+	}
+	for _, test := range tests {
+		ttl, ok := opts.TTLFor(test.lab)
+		if ttl != test.ttl || ok != test.ok {
+			t.Errorf("%s: got %s %t, want %s %t", test.lab, ttl, ok, test.ttl, test.ok)
+		}
+	}
+}
+
+func TestNewReaper_StateFile(t *testing.T) {
+	state := filepath.Join(t.TempDir(), "reaper.json")
+	if _, err := evengsdk.NewReaper(nil, evengsdk.ReaperOptions{StateFile: state}); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(state, []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := evengsdk.NewReaper(nil, evengsdk.ReaperOptions{StateFile: state}); err == nil {
+		t.Fatal("Should have failed on the invalid state file")
+	}
+}
+
+func TestReaper_RunInterval(t *testing.T) {
+	reaper, err := evengsdk.NewReaper(&evengsdk.Client{}, evengsdk.ReaperOptions{TTL: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, interval := range []time.Duration{0, -time.Second} {
+		if err := reaper.Run(context.Background(), interval, nil); err == nil {
+			t.Fatalf("interval %v should have been rejected", interval)
+		}
+	}
+}
+
+func TestReaper_ScanCanceled(t *testing.T) {
+	client := apiServer(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s", r.URL.Path)
+		http.NotFound(w, r)
+	})
+	reaper, err := evengsdk.NewReaper(client, evengsdk.ReaperOptions{TTL: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = reaper.Scan(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the context error, got %v", err)
+	}
+}
+
+func TestReaper_Scan(t *testing.T) {
+	client, err := evengsdk.NewBasicAuthClient(os.Getenv("EVE_USER"), os.Getenv("EVE_PASSWORD"), "0", os.Getenv("EVE_HOST"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The reaper only scans its own folder, so that other labs on the server are left alone.
+	folder := "/reaper-" + time.Now().Format("15-04-05")
+	if err := client.Folder.CreateFolder(folder); err != nil {
+		t.Fatal(err)
+	}
+	defer client.Folder.DeleteFolder(folder)
+	lab := folder + "/lab.unl"
+	err = client.Lab.CreateLab(lab, evengsdk.Lab{Name: "lab", Description: "Reaper"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Lab.DeleteLab(lab)
+	node := &evengsdk.Node{Name: "PC1", Template: "vpcs", Type: evengsdk.NodeTypeVpcs, Console: evengsdk.ConsoleTelnet}
+	if err := client.Node.CreateNode(lab, node); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Node.StartNode(lab, node.Id); err != nil {
+		t.Fatal(err)
+	}
+	defer client.Node.StopNodes(lab)
+
+	state := filepath.Join(t.TempDir(), "reaper.json")
+	opts := evengsdk.ReaperOptions{Root: folder, TTL: time.Second, DryRun: true, StateFile: state}
+	reaper, err := evengsdk.NewReaper(client, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if _, err := reaper.Scan(ctx); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(2 * time.Second)
+
+	// A new reaper picks up the running times from the state file.
+	opts.DryRun = false
+	reaper, err = evengsdk.NewReaper(client, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actions, err := reaper.Scan(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var found bool
+	for _, action := range actions {
+		if action.Lab == lab {
+			found = true
+			if action.Err != nil || len(action.Nodes) != 1 {
+				t.Fatalf("unexpected action %s", action)
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("%s was not reaped: %v", lab, actions)
+	}
+	current, err := client.Node.GetNode(lab, node.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current.Status.Running() {
+		t.Fatal("Node should have been stopped")
+	}
+}
@@ -0,0 +1,131 @@
+package test
+
+import (
+	"bytes"
+	"github.com/CorentinPtrl/evengsdk"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func renderTopology() *evengsdk.Topology {
+	return &evengsdk.Topology{
+		Nodes: map[string]evengsdk.Node{
+			"1": {Id: 1, Name: "R1", Template: "vios", Left: 100, Top: 200},
+			"2": {Id: 2, Name: "R2", Template: "vios", Left: 300, Top: 200},
+		},
+		Networks: map[string]evengsdk.Network{
+			"1": {Id: 1, Name: "Net1", Type: "bridge", Visibility: "1"},
+			"2": {Id: 2, Name: "Mgmt", Type: "pnet0", Visibility: "1"},
+		},
+		Links: evengsdk.ParseTopologyLinks([]map[string]interface{}{
+			{"type": "ethernet", "source": "node1", "source_type": "node", "source_label": "Gi0/0", "destination": "network1", "destination_type": "network", "network_id": float64(1)},
+			{"type": "ethernet", "source": "node2", "source_type": "node", "source_label": "Gi0/1", "destination": "network1", "destination_type": "network", "network_id": float64(1)},
+			{"type": "ethernet", "source": "node1", "source_type": "node", "source_label": "Gi0/2", "destination": "network2", "destination_type": "network", "network_id": "2"},
+		}),
+	}
+}
+
+func TestRenderDOT(t *testing.T) {
+	var buf bytes.Buffer
+	err := evengsdk.RenderDOT(&buf, renderTopology(), evengsdk.RenderOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		`graph "lab" {`,
+		`"node1" [label="R1\nvios"];`,
+		`"network1" [label="Net1", shape=ellipse];`,
+		`"node1" -- "network1" [taillabel="Gi0/0"];`,
+		`"node2" -- "network1" [taillabel="Gi0/1"];`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("missing %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestRenderDOTCollapseBridges(t *testing.T) {
+	var buf bytes.Buffer
+	err := evengsdk.RenderDOT(&buf, renderTopology(), evengsdk.RenderOptions{CollapseBridges: true, KeepPositions: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if strings.Contains(out, `"network1"`) {
+		t.Fatalf("bridge network was not collapsed:\n%s", out)
+	}
+	for _, want := range []string{
+		`"node1" -- "node2" [taillabel="Gi0/0", headlabel="Gi0/1"];`,
+		`"node1" -- "network2" [taillabel="Gi0/2"];`,
+		`pos="100,-200!"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("missing %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestRenderMermaid(t *testing.T) {
+	var buf bytes.Buffer
+	err := evengsdk.RenderMermaid(&buf, renderTopology(), evengsdk.RenderOptions{CollapseBridges: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"flowchart LR",
+		`node1["R1<br/>vios"]`,
+		`network2(("Mgmt"))`,
+		`node1 ---|"Gi0/0 - Gi0/1"| node2`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("missing %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestRenderMermaidHiddenBridge(t *testing.T) {
+	topology := renderTopology()
+	network := topology.Networks["1"]
+	network.Visibility = "0"
+	topology.Networks["1"] = network
+	var buf bytes.Buffer
+	err := evengsdk.RenderMermaid(&buf, topology, evengsdk.RenderOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if strings.Contains(out, "network1") {
+		t.Fatalf("hidden bridge was not collapsed:\n%s", out)
+	}
+	if !strings.Contains(out, `node1 ---|"Gi0/0 - Gi0/1"| node2`) {
+		t.Fatalf("missing the link through the hidden bridge in:\n%s", out)
+	}
+}
+
+func TestLabService_GetLabTopology(t *testing.T) {
+	client, err := evengsdk.NewBasicAuthClient(os.Getenv("EVE_USER"), os.Getenv("EVE_PASSWORD"), "0", os.Getenv("EVE_HOST"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	time := time.Now()
+	err = client.Lab.CreateLab("/"+time.Format("15-04-05")+".unl", evengsdk.Lab{
+		Description: "Unit Test",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Lab.DeleteLab("/" + time.Format("15-04-05") + ".unl")
+	topology, err := client.Lab.GetLabTopology("/" + time.Format("15-04-05") + ".unl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	err = evengsdk.RenderDOT(&buf, topology, evengsdk.RenderOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
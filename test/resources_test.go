@@ -0,0 +1,133 @@
+package test
+
+import (
+	"encoding/json"
+	"github.com/CorentinPtrl/evengsdk"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckNodeResources(t *testing.T) {
+	var template evengsdk.Template
+	if err := json.Unmarshal([]byte(viosTemplate), &template); err != nil {
+		t.Fatal(err)
+	}
+	nodes := []evengsdk.Node{
+		{Id: 3, Name: "R3", Template: "vios", Ram: 4096, Cpu: 2},
+		{Id: 1, Name: "R1", Template: "vios"},
+		{Id: 2, Name: "R2", Template: "vios", Status: evengsdk.NodeStatusRunning},
+		{Id: 4, Name: "PC1", Template: "vpcs"},
+	}
+	templates := map[string]*evengsdk.Template{"vios": &template}
+	usage := evengsdk.HostUsage{Cpu: 50, Mem: 75}
+	report := evengsdk.CheckNodeResources(nodes, templates, usage, evengsdk.HostCapacity{Ram: 16384, Cpu: 4})
+	if report.Nodes[0].Name != "R1" || report.Nodes[0].Ram != 1024 || report.Nodes[0].Cpu != 1 || !report.Nodes[1].Running {
+		t.Fatalf("unexpected nodes %+v", report.Nodes)
+	}
+	if report.Ram != 5120 || report.Cpu != 3 || report.FreeRam != 4096 || report.FreeCpu != 2 || report.Fits() {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	start, skipped := report.Subset(nil)
+	if len(start) != 2 || start[0].Name != "R1" || start[1].Name != "PC1" || len(skipped) != 1 || skipped[0].Name != "R3" {
+		t.Fatalf("unexpected subset %+v, skipped %+v", start, skipped)
+	}
+
+	report = evengsdk.CheckNodeResources(nodes, templates, usage, evengsdk.HostCapacity{Ram: 32768, Cpu: 4, Overcommit: 2})
+	if !report.Fits() {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	report.FreeRam = 4096
+	start, skipped = report.Subset(func(id int) int { return id })
+	if len(start) != 2 || start[0].Name != "PC1" || start[1].Name != "R3" || len(skipped) != 1 || skipped[0].Name != "R1" {
+		t.Fatalf("unexpected subset %+v, skipped %+v", start, skipped)
+	}
+}
+
+func TestCheckNodeResources_RamOnly(t *testing.T) {
+	nodes := []evengsdk.Node{
+		{Id: 1, Name: "R1", Template: "vios", Ram: 2048, Cpu: 4},
+		{Id: 2, Name: "R2", Template: "vios", Ram: 4096, Cpu: 4},
+	}
+	// Without host CPUs, only the memory is checked.
+	report := evengsdk.CheckNodeResources(nodes, nil, evengsdk.HostUsage{Mem: 50}, evengsdk.HostCapacity{Ram: 16384})
+	if !report.Fits() {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	report = evengsdk.CheckNodeResources(nodes, nil, evengsdk.HostUsage{Mem: 75}, evengsdk.HostCapacity{Ram: 16384})
+	start, skipped := report.Subset(nil)
+	if report.Fits() || len(start) != 1 || start[0].Name != "R1" || len(skipped) != 1 || skipped[0].Name != "R2" {
+		t.Fatalf("unexpected subset %+v, skipped %+v", start, skipped)
+	}
+	err := &evengsdk.ResourceError{Report: report, Skipped: skipped}
+	if err.Error() != "Insufficient resources: need 6144 MB RAM, 4096 MB RAM free, not started: R2" {
+		t.Fatalf("unexpected error %q", err)
+	}
+}
+
+func TestNodeService_CheckResources_Errors(t *testing.T) {
+	client := apiServer(t, func(w http.ResponseWriter, r *http.Request) {
+		// Dropping the connection fails the request without retrying it.
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err == nil {
+			conn.Close()
+		}
+	})
+	nodes := []evengsdk.Node{{Id: 1, Template: "vios"}, {Id: 2, Template: "asav"}, {Id: 3, Template: "csr1000v"}}
+	_, err := client.Node.CheckResources(nodes, evengsdk.HostCapacity{Ram: 16384})
+	if err == nil {
+		t.Fatal("Should have failed")
+	}
+	var names []string
+	for _, line := range strings.Split(err.Error(), "\n") {
+		names = append(names, line[:strings.Index(line, ":")])
+	}
+	if strings.Join(names, ",") != "asav,csr1000v,vios" {
+		t.Fatalf("unexpected error order %q", err)
+	}
+}
+
+func TestNodeService_StartNodesWithOptions_NoCapacity(t *testing.T) {
+	client := apiServer(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+	})
+	err := client.Node.StartNodesWithOptions("/test.unl", evengsdk.StartOptions{Policy: evengsdk.CapacitySubset})
+	if err == nil {
+		t.Fatal("Should have failed")
+	}
+	if _, err := client.Node.CheckResources(nil, evengsdk.HostCapacity{Cpu: 4}); err == nil {
+		t.Fatal("Should have failed")
+	}
+}
+
+func TestNodeService_StartNodesCapacity(t *testing.T) {
+	client, err := evengsdk.NewBasicAuthClient(os.Getenv("EVE_USER"), os.Getenv("EVE_PASSWORD"), "0", os.Getenv("EVE_HOST"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lab := "/" + time.Now().Format("15-04-05") + ".unl"
+	err = client.Lab.CreateLab(lab, evengsdk.Lab{Name: lab[1 : len(lab)-4], Description: "Resource preflight"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Lab.DeleteLab(lab)
+	err = client.Node.CreateNode(lab, &evengsdk.Node{Name: "PC1", Template: "vpcs", Type: evengsdk.NodeTypeVpcs, Console: evengsdk.ConsoleTelnet})
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := client.Lab.CheckResources(lab, evengsdk.HostCapacity{Ram: 1 << 20, Cpu: 1 << 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Nodes) != 1 || !report.Fits() {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	// vpcs nodes need no memory, so the lab starts even on a tiny host.
+	err = client.Node.StartNodesWithOptions(lab, evengsdk.StartOptions{Capacity: evengsdk.HostCapacity{Ram: 1, Cpu: 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Node.StopNodes(lab)
+}
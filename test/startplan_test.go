@@ -0,0 +1,101 @@
+package test
+
+import (
+	"context"
+	"github.com/CorentinPtrl/evengsdk"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestPlanStart(t *testing.T) {
+	nodes := []evengsdk.Node{
+		{Id: 4, Name: "PE2"},
+		{Id: 3, Name: "PE1", Delay: 10},
+		{Id: 2, Name: "RR2"},
+		{Id: 1, Name: "RR1"},
+		{Id: 5, Name: "CE1"},
+	}
+	plan, err := evengsdk.PlanStart(nodes, evengsdk.StartPlanOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan) != 1 || plan[0].Nodes[0].Name != "RR1" || plan[0].Nodes[4].Name != "PE1" {
+		t.Fatalf("unexpected plan %+v", plan)
+	}
+
+	opts := evengsdk.StartPlanOptions{
+		Dependencies: map[string][]string{"PE1": {"RR1", "RR2"}, "PE2": {"RR1", "RR2"}, "CE1": {"PE1"}},
+		Wave: func(node evengsdk.Node) int {
+			if node.Name == "RR2" {
+				return 1
+			}
+			return 0
+		},
+	}
+	plan, err = evengsdk.PlanStart(nodes, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got [][]string
+	for _, wave := range plan {
+		var names []string
+		for _, node := range wave.Nodes {
+			names = append(names, node.Name)
+		}
+		got = append(got, names)
+	}
+	want := [][]string{{"RR1"}, {"RR2"}, {"PE2", "PE1"}, {"CE1"}}
+	if len(got) != len(want) {
+		t.Fatalf("unexpected plan %v", got)
+	}
+	for i := range want {
+		if len(got[i]) != len(want[i]) || got[i][0] != want[i][0] || got[i][len(got[i])-1] != want[i][len(want[i])-1] {
+			t.Fatalf("unexpected plan %v", got)
+		}
+	}
+
+	opts.Dependencies["RR1"] = []string{"CE1"}
+	if _, err := evengsdk.PlanStart(nodes, opts); err == nil {
+		t.Fatal("Should have failed on the dependency cycle")
+	}
+	opts.Dependencies = map[string][]string{"PE1": {"RR3"}}
+	if _, err := evengsdk.PlanStart(nodes, opts); err == nil {
+		t.Fatal("Should have failed on the unknown dependency")
+	}
+}
+
+func TestNodeService_StartOrdered(t *testing.T) {
+	client, err := evengsdk.NewBasicAuthClient(os.Getenv("EVE_USER"), os.Getenv("EVE_PASSWORD"), "0", os.Getenv("EVE_HOST"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lab := "/" + time.Now().Format("15-04-05") + ".unl"
+	err = client.Lab.CreateLab(lab, evengsdk.Lab{Name: lab[1 : len(lab)-4], Description: "Ordered start"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Lab.DeleteLab(lab)
+	for _, name := range []string{"PC1", "PC2"} {
+		err = client.Node.CreateNode(lab, &evengsdk.Node{Name: name, Template: "vpcs", Type: evengsdk.NodeTypeVpcs, Console: evengsdk.ConsoleTelnet})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	opts := evengsdk.StartPlanOptions{
+		Dependencies: map[string][]string{"PC2": {"PC1"}},
+		Ready:        evengsdk.StatusReady{Interval: time.Second},
+		ReadyTimeout: time.Minute,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	if err := client.Node.StartOrdered(ctx, lab, opts); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Node.WaitAllReady(ctx, lab, evengsdk.StatusReady{Interval: time.Second}); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Node.StopOrdered(ctx, lab, opts); err != nil {
+		t.Fatal(err)
+	}
+}
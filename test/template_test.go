@@ -0,0 +1,85 @@
+package test
+
+import (
+	"encoding/json"
+	"github.com/CorentinPtrl/evengsdk"
+	"os"
+	"testing"
+	"time"
+)
+
+const viosTemplate = `{
+	"description": "Cisco vIOS Router",
+	"type": "qemu",
+	"options": {
+		"template": {"name": "Template", "type": "input", "value": "vios"},
+		"type": {"name": "Type", "type": "input", "value": "qemu"},
+		"name": {"name": "Name/prefix", "type": "input", "value": "vIOS"},
+		"icon": {"name": "Icon", "type": "list", "value": "Router.png", "list": {"Router.png": "Router.png", "Switch.png": "Switch.png"}},
+		"image": {"name": "Image", "type": "list", "value": "vios-adventerprisek9-m-15.6.2T", "list": ["vios-adventerprisek9-m-15.6.2T", "vios-adventerprisek9-m-15.9.3M6"]},
+		"cpu": {"name": "CPU", "type": "input", "value": 1},
+		"ram": {"name": "RAM (MB)", "type": "input", "value": "1024"},
+		"ethernet": {"name": "Ethernets", "type": "input", "value": 4, "min": 1, "max": 16},
+		"console": {"name": "Console", "type": "list", "value": "telnet", "list": {"telnet": "telnet", "vnc": "vnc"}},
+		"config": {"name": "Startup configuration", "type": "list", "value": "Unconfigured", "list": {"0": "None", "1": "Exported"}},
+		"qemu_nic": {"name": "QEMU Nic", "type": "input", "value": "e1000"},
+		"extra_option": {"name": "Extra", "type": "input", "value": "yes"}
+	}
+}`
+
+func TestTemplate_NewNode(t *testing.T) {
+	var template evengsdk.Template
+	if err := json.Unmarshal([]byte(viosTemplate), &template); err != nil {
+		t.Fatal(err)
+	}
+	template.Name = "vios"
+	if template.Type != evengsdk.NodeTypeQemu || len(template.Images()) != 2 || template.Options["cpu"].Min != 1 {
+		t.Fatalf("unexpected template %+v", template)
+	}
+	node, err := template.NewNode(evengsdk.Node{Name: "R1", Ram: 2048, Image: "vios-adventerprisek9-m-15.9.3M6"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if node.Name != "R1" || node.Ram != 2048 || node.Cpu != 1 || node.Ethernet != 4 || node.Icon != "Router.png" ||
+		node.Console != evengsdk.ConsoleTelnet || node.Template != "vios" || node.Type != evengsdk.NodeTypeQemu ||
+		node.QemuNic != "e1000" || node.Config != "" || string(node.Extra["extra_option"]) != `"yes"` {
+		t.Fatalf("unexpected node %+v", node)
+	}
+	for _, overrides := range []evengsdk.Node{
+		{Image: "vios-missing"},
+		{Ethernet: 32},
+		{Ram: -1},
+		{Console: evengsdk.ConsoleRdp},
+		{Type: evengsdk.NodeTypeIol, Console: evengsdk.ConsoleVnc},
+	} {
+		if _, err := template.NewNode(overrides); err == nil {
+			t.Fatalf("%+v should have been rejected", overrides)
+		}
+	}
+}
+
+func TestNodeService_NewNodeFromTemplate(t *testing.T) {
+	client, err := evengsdk.NewBasicAuthClient(os.Getenv("EVE_USER"), os.Getenv("EVE_PASSWORD"), "0", os.Getenv("EVE_HOST"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lab := "/" + time.Now().Format("15-04-05") + ".unl"
+	err = client.Lab.CreateLab(lab, evengsdk.Lab{
+		Description: "Unit Test",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Lab.DeleteLab(lab)
+	node, err := client.Node.NewNodeFromTemplate("vpcs", evengsdk.Node{Name: "vpc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = client.Node.CreateNode(lab, node)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Node.NewNodeFromTemplate("vpcs", evengsdk.Node{Console: evengsdk.ConsoleVnc}); err == nil {
+		t.Fatal("Should have failed")
+	}
+}
@@ -0,0 +1,100 @@
+package test
+
+import (
+	"context"
+	"github.com/CorentinPtrl/evengsdk"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDiffNodes(t *testing.T) {
+	r1 := evengsdk.Node{Id: 1, Name: "R1", Template: "vios", Status: evengsdk.NodeStatusStopped}
+	running := r1
+	running.Status = evengsdk.NodeStatusRunning
+	renamed := r1
+	renamed.Name = "R1-renamed"
+	limited := r1
+	limited.CpuLimit = 1
+	for _, test := range []struct {
+		name     string
+		previous map[int]evengsdk.Node
+		current  map[int]evengsdk.Node
+		want     []evengsdk.NodeEvent
+	}{
+		{"unchanged", map[int]evengsdk.Node{1: r1}, map[int]evengsdk.Node{1: r1}, nil},
+		{"added", map[int]evengsdk.Node{}, map[int]evengsdk.Node{1: r1}, []evengsdk.NodeEvent{{Type: evengsdk.NodeAdded, Node: r1}}},
+		{"removed", map[int]evengsdk.Node{1: r1}, map[int]evengsdk.Node{}, []evengsdk.NodeEvent{{Type: evengsdk.NodeRemoved, Node: r1}}},
+		{"started", map[int]evengsdk.Node{1: r1}, map[int]evengsdk.Node{1: running}, []evengsdk.NodeEvent{
+			{Type: evengsdk.NodeStarted, Node: running, Changes: []evengsdk.FieldChange{{Field: "Status", Old: evengsdk.NodeStatusStopped, New: evengsdk.NodeStatusRunning}}},
+		}},
+		{"stopped", map[int]evengsdk.Node{1: running}, map[int]evengsdk.Node{1: r1}, []evengsdk.NodeEvent{
+			{Type: evengsdk.NodeStopped, Node: r1, Changes: []evengsdk.FieldChange{{Field: "Status", Old: evengsdk.NodeStatusRunning, New: evengsdk.NodeStatusStopped}}},
+		}},
+		{"changed", map[int]evengsdk.Node{1: r1}, map[int]evengsdk.Node{1: renamed}, []evengsdk.NodeEvent{
+			{Type: evengsdk.NodeChanged, Node: renamed, Changes: []evengsdk.FieldChange{{Field: "Name", Old: "R1", New: "R1-renamed"}}},
+		}},
+		{"embedded settings", map[int]evengsdk.Node{1: r1}, map[int]evengsdk.Node{1: limited}, []evengsdk.NodeEvent{
+			{Type: evengsdk.NodeChanged, Node: limited, Changes: []evengsdk.FieldChange{{Field: "CpuLimit", Old: 0, New: 1}}},
+		}},
+		{"sorted by id", map[int]evengsdk.Node{2: {Id: 2}}, map[int]evengsdk.Node{1: r1, 3: {Id: 3}}, []evengsdk.NodeEvent{
+			{Type: evengsdk.NodeAdded, Node: r1},
+			{Type: evengsdk.NodeRemoved, Node: evengsdk.Node{Id: 2}},
+			{Type: evengsdk.NodeAdded, Node: evengsdk.Node{Id: 3}},
+		}},
+	} {
+		got := evengsdk.DiffNodes(test.previous, test.current)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Fatalf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestNodeService_Watch(t *testing.T) {
+	client, err := evengsdk.NewBasicAuthClient(os.Getenv("EVE_USER"), os.Getenv("EVE_PASSWORD"), "0", os.Getenv("EVE_HOST"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lab := "/" + time.Now().Format("15-04-05") + ".unl"
+	err = client.Lab.CreateLab(lab, evengsdk.Lab{
+		Description: "Unit Test",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Lab.DeleteLab(lab)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := client.Node.Watch(ctx, lab, 500*time.Millisecond)
+	time.Sleep(time.Second)
+	node := &evengsdk.Node{
+		Name:     "vpc",
+		Template: "vpcs",
+		Type:     "vpcs",
+	}
+	err = client.Node.CreateNode(lab, node)
+	if err != nil {
+		t.Fatal(err)
+	}
+	event := <-events
+	if event.Type != evengsdk.NodeAdded || event.Node.Name != "vpc" {
+		t.Fatalf("unexpected event %v", event)
+	}
+	err = client.Node.StartNode(lab, node.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Node.StopNodes(lab)
+	for event = range events {
+		if event.Type == evengsdk.NodeStarted {
+			break
+		}
+	}
+	if event.Node.Id != node.Id {
+		t.Fatalf("unexpected event %v", event)
+	}
+	cancel()
+	for range events {
+	}
+}
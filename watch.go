@@ -0,0 +1,194 @@
+package evengsdk
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// NodeEventType is the kind of a NodeEvent.
+type NodeEventType int
+
+const (
+	NodeAdded NodeEventType = iota
+	NodeRemoved
+	NodeStarted
+	NodeStopped
+	// NodeChanged reports changes of fields other than the status transitions above.
+	NodeChanged
+	// NodeWatchError reports a failed poll, the watcher keeps polling with a backoff.
+	NodeWatchError
+)
+
+func (t NodeEventType) String() string {
+	switch t {
+	case NodeAdded:
+		return "added"
+	case NodeRemoved:
+		return "removed"
+	case NodeStarted:
+		return "started"
+	case NodeStopped:
+		return "stopped"
+	case NodeChanged:
+		return "changed"
+	case NodeWatchError:
+		return "error"
+	}
+	return "unknown"
+}
+
+// FieldChange is the change of a single Node field.
+type FieldChange struct {
+	Field string
+	Old   interface{}
+	New   interface{}
+}
+
+// NodeEvent is emitted by Watch.
+// Node is the current state of the node, or its last known state when it was removed.
+type NodeEvent struct {
+	Type    NodeEventType
+	Node    Node
+	Changes []FieldChange
+	Time    time.Time
+	Err     error
+}
+
+const (
+	// minWatchInterval is the shortest delay between polls, shorter intervals are raised to it.
+	minWatchInterval = 100 * time.Millisecond
+	// maxWatchBackoff bounds the delay between polls after consecutive errors.
+	maxWatchBackoff = time.Minute
+)
+
+// Watch polls the nodes in the specified path every interval and emits an event for every difference between two polls.
+// The path should be the full path to the lab file, including the extension (e.g. /path/to/labfile.unl).
+// The first poll only records the current state. The channel is closed once the context is done.
+// Intervals shorter than 100ms are raised to 100ms.
+func (s *NodeService) Watch(ctx context.Context, path string, interval time.Duration) <-chan NodeEvent {
+	interval = max(interval, minWatchInterval)
+	events := make(chan NodeEvent)
+	go func() {
+		defer close(events)
+		var previous map[int]Node
+		delay := interval
+		for {
+			nodes, err := s.GetNodes(path)
+			now := time.Now()
+			if err != nil {
+				if !watchEmit(ctx, events, NodeEvent{Type: NodeWatchError, Time: now, Err: err}) {
+					return
+				}
+				delay = min(delay*2, max(maxWatchBackoff, interval))
+			} else {
+				delay = interval
+				current := make(map[int]Node, len(nodes))
+				for _, node := range nodes {
+					current[node.Id] = node
+				}
+				if previous != nil {
+					for _, event := range DiffNodes(previous, current) {
+						event.Time = now
+						if !watchEmit(ctx, events, event) {
+							return
+						}
+					}
+				}
+				previous = current
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+		}
+	}()
+	return events
+}
+
+func watchEmit(ctx context.Context, events chan<- NodeEvent, event NodeEvent) bool {
+	select {
+	case events <- event:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// DiffNodes returns the events between two snapshots of the nodes of a lab keyed by node id, sorted by node id.
+// The events are the ones emitted by Watch, without their Time.
+func DiffNodes(previous, current map[int]Node) []NodeEvent {
+	ids := make([]int, 0, len(previous)+len(current))
+	for id := range previous {
+		ids = append(ids, id)
+	}
+	for id := range current {
+		if _, ok := previous[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+
+	var events []NodeEvent
+	for _, id := range ids {
+		old, existed := previous[id]
+		node, exists := current[id]
+		switch {
+		case !existed:
+			events = append(events, NodeEvent{Type: NodeAdded, Node: node})
+		case !exists:
+			events = append(events, NodeEvent{Type: NodeRemoved, Node: old})
+		default:
+			changes := diffNode(old, node)
+			var rest []FieldChange
+			for _, change := range changes {
+				if change.Field != "Status" {
+					rest = append(rest, change)
+					continue
+				}
+				switch {
+				case node.Status.Running() && !old.Status.Running():
+					events = append(events, NodeEvent{Type: NodeStarted, Node: node, Changes: []FieldChange{change}})
+				case old.Status.Running() && !node.Status.Running():
+					events = append(events, NodeEvent{Type: NodeStopped, Node: node, Changes: []FieldChange{change}})
+				default:
+					rest = append(rest, change)
+				}
+			}
+			if len(rest) > 0 {
+				events = append(events, NodeEvent{Type: NodeChanged, Node: node, Changes: rest})
+			}
+		}
+	}
+	return events
+}
+
+// diffNode returns the fields that differ between two versions of a node.
+// The fields of the embedded settings are reported by their own name.
+func diffNode(old, node Node) []FieldChange {
+	return diffFields(reflect.ValueOf(old), reflect.ValueOf(node))
+}
+
+func diffFields(a, b reflect.Value) []FieldChange {
+	var changes []FieldChange
+	for i := 0; i < a.NumField(); i++ {
+		field := a.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			changes = append(changes, diffFields(a.Field(i), b.Field(i))...)
+			continue
+		}
+		if !reflect.DeepEqual(a.Field(i).Interface(), b.Field(i).Interface()) {
+			changes = append(changes, FieldChange{
+				Field: field.Name,
+				Old:   a.Field(i).Interface(),
+				New:   b.Field(i).Interface(),
+			})
+		}
+	}
+	return changes
+}